@@ -0,0 +1,83 @@
+package main
+
+import (
+	"flag"
+	"image/png"
+	"log"
+	"os"
+	"path/filepath"
+
+	"minego/internal/cell"
+	"minego/internal/identify"
+	"minego/internal/theme"
+)
+
+// 从保存的雷区截图标定主题：提供标注时推导全部颜色与模板，否则按新开局（全部未翻开）标定
+func main() {
+	imagePath := flag.String("image", "mineField.png", "雷区截图 PNG")
+	labelsPath := flag.String("labels", "", "截图对应的文本盘面标注，格式同 GridcellRec.txt；为空时视为新开局")
+	out := flag.String("out", "theme.json", "输出主题文件")
+	templateDir := flag.String("templates", "templates", "模板输出目录，相对路径以主题文件所在目录为基准")
+	name := flag.String("name", "calibrated", "主题名称")
+	flag.Parse()
+
+	file, err := os.Open(*imagePath)
+	if err != nil {
+		log.Fatalf("打开截图失败: %v", err)
+	}
+	img, err := png.Decode(file)
+	file.Close()
+	if err != nil {
+		log.Fatalf("解码截图失败: %v", err)
+	}
+
+	if profile, err := theme.Detect(img, theme.Profiles()); err == nil {
+		log.Printf("🔎 截图配色与客户端 %s 最吻合", profile.Name)
+	}
+
+	var labels [][]cell.GridCell
+	if *labelsPath != "" {
+		labels, err = identify.LoadResultFromFile(*labelsPath)
+		if err != nil {
+			log.Fatalf("读取标注失败: %v", err)
+		}
+	}
+
+	calibration, err := theme.Calibrate(img, labels)
+	if err != nil {
+		log.Fatalf("标定失败: %v", err)
+	}
+	t := calibration.Theme
+	t.Name = *name
+	templateCount := 0
+	if calibration.Templates != nil {
+		t.Templates = *templateDir
+		dir := *templateDir
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(filepath.Dir(*out), dir)
+		}
+		if err := calibration.Templates.SaveTemplates(dir); err != nil {
+			log.Fatalf("保存模板失败: %v", err)
+		}
+		templateCount = len(calibration.Templates.Templates)
+	}
+	if err := t.Save(*out); err != nil {
+		log.Fatalf("保存主题失败: %v", err)
+	}
+	log.Printf("🎨 网格 %dx%d, 单元格 %.1f 像素, 边框 %s, 覆盖格 %s（容差 %d）, 空白格 %s, 阈值 %d",
+		len(calibration.HorizontalLines)-1, len(calibration.VerticalLines)-1, t.CellSize,
+		hex(t.BorderColor), hex(t.CoveredColor), t.CoveredRange, hex(t.RevealedColor), t.BinarizeThreshold)
+	if labels != nil {
+		log.Printf("🎨 地雷 %s, 踩雷 %s, 插错旗帜 %s, 问号 %s（标注中没有的状态为 -，保留内置的占位值）",
+			hex(t.MineColor), hex(t.ExplodedColor), hex(t.MisflagColor), hex(t.QuestionColor))
+	}
+	log.Printf("✅ 已写入主题 %s 与 %d 个模板", *out, templateCount)
+}
+
+func hex(c theme.Color) string {
+	if !c.IsSet() {
+		return "-"
+	}
+	text, _ := c.MarshalText()
+	return string(text)
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	"minego/internal/calibration"
+	"minego/pkg/kit"
+)
+
+// 读取猜测日志，按预测概率分箱对比实际踩雷比例，输出校准表与可靠性图
+func main() {
+	logPath := flag.String("log", "guesses.jsonl", "猜测日志文件")
+	bins := flag.Int("bins", 10, "分箱数量")
+	source := flag.String("source", "", "只统计指定来源（sim 或 live），为空时统计全部")
+	strategy := flag.String("strategy", "", "只统计指定求解器配置，为空时统计全部")
+	out := flag.String("out", "calibration.png", "可靠性图输出路径")
+	flag.Parse()
+	if *bins <= 0 {
+		log.Fatalf("分箱数量须为正数: %d", *bins)
+	}
+
+	records, err := calibration.Load(*logPath)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	filtered := records[:0]
+	for _, r := range records {
+		if (*source == "" || r.Source == *source) && (*strategy == "" || r.Strategy == *strategy) {
+			filtered = append(filtered, r)
+		}
+	}
+	log.Printf("📈 共 %d 条猜测记录", len(filtered))
+
+	b := calibration.Bins(filtered, *bins)
+	calibration.WriteTable(os.Stdout, b)
+	if err := kit.SaveImg(calibration.Chart(b, 400), *out); err != nil {
+		log.Fatalf("保存可靠性图失败: %v", err)
+	}
+	log.Printf("💾 已保存 %s", *out)
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"minego/internal/cell"
+	"minego/internal/dataset"
+	"minego/internal/identify"
+)
+
+// 校对由 cmd/main.go -dataset 导出的单元格数据集并重建识别模板。
+// 标注即文件所在的状态目录：可以直接在文件管理器中移动文件，也可以用 -relabel 批量修改
+func main() {
+	dir := flag.String("dir", "dataset", "数据集目录")
+	relabels := make([]string, 0)
+	flag.Func("relabel", "修改标注，形如 20261019-150405.000_r03_c07.png=flagged，可重复", func(s string) error {
+		relabels = append(relabels, s)
+		return nil
+	})
+	templateDir := flag.String("templates", "", "由数据集重建模板并写入该目录")
+	perState := flag.Int("per-state", 5, "重建模板时每种状态保留的样本数")
+	doubtful := flag.Float64("doubtful", identify.MinConfidence, "列出识别置信度低于该值且尚未改标注的样本")
+	flag.Parse()
+
+	for _, r := range relabels {
+		name, stateName, ok := strings.Cut(r, "=")
+		state, known := identify.StateFromFileName(stateName)
+		if !ok || !known {
+			log.Fatalf("无效的标注修改 %q，状态可选: %s", r, strings.Join(identify.StateFileNames(), ", "))
+		}
+		if err := dataset.Relabel(*dir, name, state); err != nil {
+			log.Fatalf("修改标注失败: %v", err)
+		}
+		log.Printf("🏷️ %s → %s", name, stateName)
+	}
+
+	samples, err := dataset.Load(*dir)
+	if err != nil {
+		log.Fatalf("加载数据集失败: %v", err)
+	}
+	if len(samples) == 0 {
+		log.Fatalf("数据集 %s 中没有样本", *dir)
+	}
+
+	counts := make(map[cell.CellState]int)
+	corrected := make(map[cell.CellState]int)
+	for _, s := range samples {
+		counts[s.Label]++
+		if s.Corrected() {
+			corrected[s.Label]++
+		} else if s.Predicted != "" && s.Confidence < *doubtful {
+			fmt.Printf("❓ %s 置信度 %.2f（来源 %s 第 %d 行第 %d 列）\n", s.File, s.Confidence, s.Source, s.Row+1, s.Col+1)
+		}
+	}
+	log.Printf("🗂️ 数据集 %s 共 %d 个样本:", *dir, len(samples))
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "状态\t样本数\t改过标注")
+	states := make([]cell.CellState, 0, len(counts))
+	for state := range counts {
+		states = append(states, state)
+	}
+	sort.Slice(states, func(a, b int) bool { return states[a] < states[b] })
+	for _, state := range states {
+		fmt.Fprintf(w, "%s\t%d\t%d\n", identify.StateFileName(state), counts[state], corrected[state])
+	}
+	w.Flush()
+
+	if *templateDir != "" {
+		templates, err := dataset.Templates(samples, *perState)
+		if err != nil {
+			log.Fatalf("重建模板失败: %v", err)
+		}
+		if err := templates.SaveTemplates(*templateDir); err != nil {
+			log.Fatalf("保存模板失败: %v", err)
+		}
+		log.Printf("✅ 已将 %d 个模板写入 %s", len(templates.Templates), *templateDir)
+	}
+}
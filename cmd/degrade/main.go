@@ -0,0 +1,97 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"image/png"
+	"log"
+	"os"
+	"path/filepath"
+
+	"minego/internal/degrade"
+	"minego/internal/identify"
+	"minego/internal/render"
+)
+
+// 渲染真值盘面并施加各种图像退化，输出每种状态的识别准确率；
+// 指定 -image 与 -labels 或 -dataset 时改为评估真实截图
+func main() {
+	rows := flag.Int("rows", 16, "行数")
+	cols := flag.Int("cols", 30, "列数")
+	mines := flag.Int("mines", 99, "地雷数")
+	seed := flag.Uint64("seed", 1, "随机种子")
+	cellSize := flag.Int("cell", render.DefaultCellSize, "渲染单元格边长（像素）")
+	recognizerName := flag.String("recognizer", "color", "识别器: color、template 或 shape")
+	templateDir := flag.String("templates", "", "模板目录，应与评估的截图不同源；为空时从渲染器提取模板，此时只能评估真实截图（仅 template 识别器）")
+	imagePath := flag.String("image", "", "评估的雷区截图 PNG，需同时指定 -labels")
+	labelsPath := flag.String("labels", "", "截图对应的文本盘面标注，格式同 GridcellRec.txt")
+	datasetDir := flag.String("dataset", "", "评估 cmd/dataset 校对过的数据集目录")
+	flag.Parse()
+
+	captured := *imagePath != "" || *datasetDir != ""
+	if *recognizerName == "template" && *templateDir == "" && !captured {
+		log.Fatalf("渲染器提取的模板与合成盘面同源，准确率没有意义；请用 -templates 指定从截图提取的模板，或用 -image/-dataset 评估真实截图")
+	}
+
+	var recognizer identify.Recognizer
+	switch *recognizerName {
+	case "color":
+		recognizer = identify.ColorRecognizer{}
+	case "shape":
+		recognizer = identify.ShapeRecognizer{}
+	case "template":
+		if *templateDir == "" {
+			recognizer = degrade.RenderedTemplates(*cellSize)
+			break
+		}
+		templates, err := identify.LoadTemplates(*templateDir)
+		if err != nil {
+			log.Fatalf("加载模板失败: %v", err)
+		}
+		recognizer = templates
+	default:
+		log.Fatalf("未知识别器: %s", *recognizerName)
+	}
+
+	switch {
+	case *datasetDir != "":
+		report, err := degrade.EvaluateDataset(recognizer, *datasetDir)
+		if err != nil {
+			log.Fatalf("评估数据集失败: %v", err)
+		}
+		log.Printf("📊 数据集 %s 的识别准确率 (%s):", *datasetDir, *recognizerName)
+		degrade.WriteTable(os.Stdout, []degrade.Report{report})
+	case *imagePath != "":
+		if *labelsPath == "" {
+			log.Fatalf("评估截图需要用 -labels 指定标注")
+		}
+		report, err := evaluateCapture(recognizer, *imagePath, *labelsPath)
+		if err != nil {
+			log.Fatalf("评估截图失败: %v", err)
+		}
+		log.Printf("📊 截图 %s 的识别准确率 (%s):", *imagePath, *recognizerName)
+		degrade.WriteTable(os.Stdout, []degrade.Report{report})
+	default:
+		truth := degrade.SampleBoard(*rows, *cols, *mines, *seed)
+		reports := degrade.EvaluateWith(recognizer, truth, *cellSize, degrade.Standard())
+		log.Printf("📊 %dx%d 盘面在各退化下的识别准确率 (%s):", *rows, *cols, *recognizerName)
+		degrade.WriteTable(os.Stdout, reports)
+	}
+}
+
+func evaluateCapture(recognizer identify.Recognizer, imagePath, labelsPath string) (degrade.Report, error) {
+	file, err := os.Open(imagePath)
+	if err != nil {
+		return degrade.Report{}, fmt.Errorf("打开截图失败: %v", err)
+	}
+	img, err := png.Decode(file)
+	file.Close()
+	if err != nil {
+		return degrade.Report{}, fmt.Errorf("解码截图失败: %v", err)
+	}
+	labels, err := identify.LoadResultFromFile(labelsPath)
+	if err != nil {
+		return degrade.Report{}, fmt.Errorf("读取标注失败: %v", err)
+	}
+	return degrade.EvaluateCapture(recognizer, filepath.Base(imagePath), img, labels)
+}
@@ -1,17 +1,29 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"image"
 
 	"log"
 
-	"image/color"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"time"
 
+	"minego/internal/calibration"
+	"minego/internal/cell"
+	"minego/internal/clicker"
+	"minego/internal/dataset"
+	game "minego/internal/game"
+	"minego/internal/header"
 	"minego/internal/identify"
 	"minego/internal/imgpos"
+	"minego/internal/render"
 	"minego/internal/solver"
+	"minego/internal/theme"
 	"minego/internal/window"
 
 	"minego/pkg/imageproc"
@@ -22,55 +34,118 @@ import (
 	"minego/pkg/screenshot"
 )
 
-var (
-	BorderColor = color.RGBA{7, 8, 9, 255}
-)
-
-// main 函数是程序的入口点，用于执行扫雷游戏识别任务
-// 主要流程包括：截图、定位扫雷区域、裁剪图像、保存中间结果、网格检测和雷区识别
-const (
-	windowBorderInset = 10 // 窗口边界内缩像素
-	gridBorderExpand  = 3  // 雷区边界扩展像素
-)
-
-func getMineFieldBounds() (image.Rectangle, error) {
-	mineSweeperWindow := window.GetMineSweeperWindow()
+// getMineFieldBounds 按客户端配置定位窗口与雷区，返回雷区与其上方信息栏在屏幕上的范围
+func getMineFieldBounds(profile *theme.Profile) (image.Rectangle, image.Rectangle, error) {
+	mineSweeperWindow, err := window.Find(profile.WindowClass, profile.WindowTitle, profile.TitlePrefix)
+	if err != nil {
+		return image.Rectangle{}, image.Rectangle{}, fmt.Errorf("查找 %s 窗口失败: %v", profile.Name, err)
+	}
 	mineSweeperWindow.Activate()
 
 	time.Sleep(50 * time.Millisecond)
 
 	windowBounds, err := mineSweeperWindow.GetBounds()
 	if err != nil {
-		log.Fatalf("获取窗口边界失败: %v", err)
+		return image.Rectangle{}, image.Rectangle{}, fmt.Errorf("获取窗口边界失败: %v", err)
 	}
 
 	// 安全调整窗口边界
-	windowBounds.Min.X += windowBorderInset
-	windowBounds.Min.Y += windowBorderInset
-	windowBounds.Max.X -= windowBorderInset
-	windowBounds.Max.Y -= windowBorderInset
+	windowBounds = windowBounds.Inset(profile.BorderInset)
 	windowImg, err := screenshot.CaptureRect(windowBounds)
 	if err != nil {
-		log.Fatalf("截图失败: %v", err)
+		return image.Rectangle{}, image.Rectangle{}, fmt.Errorf("截图失败: %v", err)
+	}
+	mineField, err := profile.FindBoard(windowImg)
+	if err != nil {
+		return image.Rectangle{}, image.Rectangle{}, err
 	}
-	mineField := kit.FindSurroundingRect(windowImg, BorderColor)
-	mineFieldBounds := image.Rect(
-		windowBounds.Min.X+mineField.Min.X,
-		windowBounds.Min.Y+mineField.Min.Y,
-		windowBounds.Min.X+mineField.Dx()+mineField.Min.X,
-		windowBounds.Min.Y+mineField.Dy()+mineField.Min.Y)
-
-	fmt.Println("雷区边界:", mineFieldBounds)
-	mineFieldBounds.Min.X -= gridBorderExpand
-	mineFieldBounds.Min.Y -= gridBorderExpand
-	mineFieldBounds.Max.X += gridBorderExpand
-	mineFieldBounds.Max.Y += gridBorderExpand
+	mineFieldBounds := mineField.Add(windowBounds.Min)
 	fmt.Println("最终雷区边界:", mineFieldBounds)
-	return mineFieldBounds, nil
+	return mineFieldBounds, header.Region(windowBounds, mineFieldBounds), nil
+}
+
+// selectProfile 返回指定客户端配置；name 为 auto 时在能找到窗口的客户端中，
+// 按第一个找到的窗口的雷区截图用 theme.Detect 选出配色最吻合者
+func selectProfile(name string) (*theme.Profile, error) {
+	if name != "auto" {
+		return theme.ProfileByName(name)
+	}
+	var found []*theme.Profile
+	var boardImg image.Image
+	for _, p := range theme.Profiles() {
+		bounds, _, err := getMineFieldBounds(p)
+		if err != nil {
+			continue
+		}
+		if boardImg == nil {
+			if boardImg, err = screenshot.CaptureRect(bounds); err != nil {
+				return nil, fmt.Errorf("截图失败: %v", err)
+			}
+		}
+		found = append(found, p)
+	}
+	if len(found) == 0 {
+		return nil, fmt.Errorf("未找到可识别的扫雷窗口")
+	}
+	profile, err := theme.Detect(boardImg, found)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("🔎 客户端 %s 与截图最吻合（候选 %d 个）", profile.Name, len(found))
+	return profile, nil
 }
 
+// main 函数是程序的入口点，用于执行扫雷游戏识别任务
+// 主要流程包括：截图、定位扫雷区域、裁剪图像、保存中间结果、网格检测和雷区识别
 func main() {
+	client := flag.String("client", "auto", "扫雷客户端: auto, "+strings.Join(theme.ProfileNames(), ", "))
+	themePath := flag.String("theme", "theme.json", "主题文件，存在时覆盖客户端的内置配色")
+	calibrate := flag.Bool("calibrate", false, "对新开局（全部未翻开）的雷区标定主题并写入主题文件后退出")
+	templateDir := flag.String("templates", "", "模板目录，设置后使用模板识别器代替颜色识别")
+	restart := flag.Bool("restart", false, "对局结束后按 F2 开始新游戏，而不是退出")
+	shape := flag.Bool("shape", false, "按字形形状识别数字，用于所有数字同色的主题或色盲模式")
+	datasetDir := flag.String("dataset", "", "数据集目录，设置后每轮识别都导出所有单元格图像（用 cmd/dataset 校对）")
+	overlayDir := flag.String("overlay", "", "每轮识别与决策标注图的保存目录，为空时不保存")
+	flag.Parse()
+
 	click.SetDPIAware()
+	profile, err := selectProfile(*client)
+	if err != nil {
+		log.Fatalf("选择客户端失败: %v", err)
+	}
+	if err := profile.Theme.Apply(); err != nil {
+		log.Fatalf("应用 %s 配置失败: %v", profile.Name, err)
+	}
+	if profile.Theme.Templates != "" && !profile.Theme.HasTemplates() {
+		log.Printf("⚠️ 未找到 %s 的识别模板 %s，暂用颜色识别（无法区分覆盖格与空白格），请先用 cmd/calibrate 生成模板", profile.Name, profile.Theme.TemplateDir())
+	}
+	log.Printf("🪟 客户端: %s", profile.Name)
+	if _, err := os.Stat(*themePath); err == nil {
+		t, err := theme.Load(*themePath)
+		if err != nil {
+			log.Fatalf("加载主题失败: %v", err)
+		}
+		if err := t.Apply(); err != nil {
+			log.Fatalf("应用主题失败: %v", err)
+		}
+		if t.BorderColor.IsSet() {
+			profile.Theme.BorderColor = t.BorderColor
+		}
+		log.Printf("🎨 已加载主题 %s", t.Name)
+	}
+	if *templateDir != "" {
+		templates, err := identify.LoadTemplates(*templateDir)
+		if err != nil {
+			log.Fatalf("加载模板失败: %v", err)
+		}
+		identify.DefaultRecognizer = templates
+		log.Printf("🧩 已加载 %d 个识别模板", len(templates.Templates))
+	}
+	if *shape {
+		identify.DefaultRecognizer = identify.ShapeRecognizer{Base: identify.DefaultRecognizer}
+		log.Printf("🔤 按字形识别数字")
+	}
+
 	go func() {
 		err := keylistener.Listen()
 		if err != nil {
@@ -78,7 +153,7 @@ func main() {
 		}
 	}()
 
-	mineFieldBounds, err := getMineFieldBounds()
+	mineFieldBounds, headerBounds, err := getMineFieldBounds(profile)
 	if err != nil {
 		log.Fatalf("获取窗口边界失败: %v", err)
 	}
@@ -86,7 +161,54 @@ func main() {
 	if err != nil {
 		log.Fatalf("截图失败: %v", err)
 	}
-	horizontalLines, verticalLines := imageproc.DetectMineSweeperGrid(mineFieldImg)
+	if *calibrate {
+		calibrateLive(mineFieldImg, *themePath)
+		return
+	}
+	grid := imageproc.DetectMineSweeperGrid(mineFieldImg)
+	horizontalLines, verticalLines := grid.HorizontalLines, grid.VerticalLines
+	if !grid.Periodic && grid.Mode != imageproc.ThresholdFixed {
+		log.Printf("🔲 网格按 %s 阈值 %d 检测，可写入主题的 binarizeThreshold 固定下来", grid.Mode, grid.Threshold)
+	}
+
+	guessLogger, err := calibration.OpenLog("guesses.jsonl")
+	if err != nil {
+		log.Printf("⚠️ %v", err)
+	} else {
+		defer guessLogger.Close()
+	}
+	var pendingGuess *solver.Guess
+	defaultStrategy := solver.DefaultConfig.Name // 求解阶段的局部变量 solver 会遮蔽包名
+	pendingStrategy := defaultStrategy
+
+	var exporter *dataset.Writer
+	if *datasetDir != "" {
+		if exporter, err = dataset.Open(*datasetDir); err != nil {
+			log.Fatalf("打开数据集失败: %v", err)
+		}
+		defer exporter.Close()
+	}
+	if *overlayDir != "" {
+		if err := os.MkdirAll(*overlayDir, 0o755); err != nil {
+			log.Printf("⚠️ 创建标注图目录失败: %v", err)
+			*overlayDir = ""
+		}
+	}
+	overlayPrefix := time.Now().Format("20060102_150405")
+
+	minefield := game.NewMinefield(mineFieldBounds, len(horizontalLines)-1, len(verticalLines)-1)
+	session := header.Session{Client: profile.Name, Start: time.Now()}
+	counterWarned, faceWarned, unobservedWarned := false, false, false
+	captureBoard := func() (image.Image, error) {
+		return screenshot.CaptureRect(mineFieldBounds)
+	}
+	var previousImg image.Image
+	tracker := identify.NewTracker(nil, horizontalLines, verticalLines)
+	history := identify.NewHistory(nil)
+	// 截图与标注图在后台保存，退出前等待写完，否则最后一轮的图像会丢失
+	var saves sync.WaitGroup
+	defer saves.Wait()
+
 	for i := range 30 {
 
 		log.Printf("=== 第 %d 轮迭代 ===", i+1)
@@ -94,10 +216,17 @@ func main() {
 		// 1. 截图阶段
 		var total time.Duration
 		start := time.Now()
-		mineFieldImg, err := screenshot.CaptureRect(mineFieldBounds)
+		// 等待翻开、插旗动画结束，避免识别到半透明的中间帧
+		mineFieldImg, settle, err := identify.Settle(captureBoard, previousImg, horizontalLines, verticalLines)
 		if err != nil {
 			panic(err)
 		}
+		previousImg = mineFieldImg
+		if settle.Animating > 0 {
+			log.Printf("⏳ 等待动画超时 %d ms，%d 个格子仍在变化", settle.Waited.Milliseconds(), settle.Animating)
+		} else if settle.Frames > 1 {
+			log.Printf("⏳ 等待动画 %d ms（%d 帧，%d 个格子有变化）", settle.Waited.Milliseconds(), settle.Frames, settle.Changed)
+		}
 		mineFieldImgPos := imgpos.NewImageWithOffset(mineFieldImg, mineFieldBounds.Min)
 		elapsed := time.Since(start)
 		log.Printf("📸 截图耗时: %d ms", elapsed.Milliseconds())
@@ -105,23 +234,149 @@ func main() {
 
 		// 4. 图像保存阶段
 		start = time.Now()
-		go kit.SaveImg(mineFieldImg, "mineField.png")
+		saves.Add(1)
+		go func() {
+			defer saves.Done()
+			kit.SaveImg(mineFieldImg, "mineField.png")
+		}()
 		elapsed = time.Since(start)
 		log.Printf("💾 保存耗时: %d ms", elapsed.Milliseconds())
 		total += elapsed
 
 		// 5. 雷区识别阶段
 		start = time.Now()
-		cells := identify.IdentifyMinesweeper(mineFieldImgPos, horizontalLines, verticalLines)
+		cells, changed := tracker.Update(mineFieldImgPos)
+		identify.SaveResultToFile(cells, "GridcellRec.txt")
 		fmt.Println(len(cells), "x", len(cells[0]))
+		log.Printf("🔄 重新识别 %d 个格子", len(changed))
+		stats, err := identify.Refine(cells, captureBoard, 2)
+		if err != nil {
+			log.Printf("⚠️ 重新截图失败: %v", err)
+		}
+		if stats.Doubtful > 0 {
+			log.Printf("🔁 %d 个格子置信度不足，重新识别恢复 %d 个，%d 个无法观测", stats.Doubtful, stats.Recovered, stats.Ambiguous)
+			identify.SaveResultToFile(cells, "GridcellRec.txt")
+		}
+		if n := countState(cells, cell.Unobserved); !unobservedWarned && n > len(cells)*len(cells[0])/2 {
+			log.Printf("⚠️ %d 个格子无法观测，覆盖格颜色可能与主题不符，请先用 -calibrate 标定新开局", n)
+			unobservedWarned = true
+		}
+		// 鼠标指针下的格子看不清，不参与求解
+		if cursor, err := click.CursorRect(); err != nil {
+			log.Printf("⚠️ %v", err)
+		} else if cursor.Overlaps(mineFieldBounds) {
+			identify.Occlude(cells, cursor)
+		}
+		// 翻开的格子不会复原、数字不会改变，与上一轮矛盾的结果视为识别错误
+		conflicts, err := history.Check(cells, captureBoard, 2)
+		if err != nil {
+			log.Printf("⚠️ 重新截图失败: %v", err)
+		}
+		for _, c := range conflicts {
+			from, to := identify.StateName(c.From), identify.StateName(c.To)
+			switch {
+			case c.Recaptured:
+				log.Printf("🕰️ 第 %d 行第 %d 列从 %s 变为 %s 不可能，重新截图识别为 %s", c.Position.Y+1, c.Position.X+1, from, to, identify.StateName(c.Resolved))
+			case c.Accepted:
+				log.Printf("🕰️ 第 %d 行第 %d 列连续 %d 轮从 %s 变为 %s，按真实变化接受", c.Position.Y+1, c.Position.X+1, identify.MaxConflictFrames, from, to)
+			default:
+				log.Printf("🕰️ 第 %d 行第 %d 列从 %s 变为 %s 不可能，保留 %s", c.Position.Y+1, c.Position.X+1, from, to, from)
+			}
+		}
+		if len(conflicts) > 0 {
+			identify.SaveResultToFile(cells, "GridcellRec.txt")
+		}
+		if n, extent := identify.Unobserved(cells); n > 0 {
+			log.Printf("🙈 %d 个格子无法观测，位于第 %d-%d 行、第 %d-%d 列，本轮不使用也不点击", n, extent.Min.Y+1, extent.Max.Y, extent.Min.X+1, extent.Max.X)
+		}
 		elapsed = time.Since(start)
 		log.Printf("🧠 识别耗时: %d ms", elapsed.Milliseconds())
 		total += elapsed
 
+		if exporter != nil {
+			start = time.Now()
+			if n, err := exporter.Export(mineFieldImg, cells); err != nil {
+				log.Printf("⚠️ 导出数据集失败: %v", err)
+			} else {
+				log.Printf("🗂️ 导出 %d 个单元格耗时: %d ms", n, time.Since(start).Milliseconds())
+			}
+		}
+
+		// 信息栏：剩余雷数加上已插旗数即地雷总数，笑脸按钮给出对局状态
+		var face header.Face
+		faceFound := false
+		headerImg, err := captureHeader(headerBounds)
+		if err != nil {
+			if !counterWarned {
+				log.Printf("⚠️ %v", err)
+				counterWarned, faceWarned = true, true
+			}
+		} else {
+			if counters, err := header.ReadCounters(headerImg); err != nil {
+				if !counterWarned {
+					log.Printf("⚠️ 未能读取信息栏计数器: %v", err)
+					counterWarned = true
+				}
+			} else {
+				minefield.MineCount = counters.Mines + countState(cells, cell.Flagged)
+				session.Seconds = counters.Seconds
+				session.MineCount = minefield.MineCount
+				log.Printf("💣 地雷总数 %d, 剩余 %d, ⏱️ 用时 %d 秒", minefield.MineCount, counters.Mines, counters.Seconds)
+			}
+			if face, err = header.FindFace(headerImg); err != nil {
+				if !faceWarned {
+					log.Printf("⚠️ 未能定位笑脸按钮: %v", err)
+					faceWarned = true
+				}
+			} else {
+				faceFound = true
+				face.Rect = face.Rect.Sub(headerImg.Bounds().Min).Add(headerBounds.Min)
+			}
+		}
+
+		// 上一轮的猜测在本轮截图中揭晓结果
+		if pendingGuess != nil {
+			resolveGuess(guessLogger, *pendingGuess, pendingStrategy, cells)
+			pendingGuess = nil
+		}
+
+		// 对局结束后不再点击；笑脸按钮的状态比盘面识别更可靠，找到时以其为准
+		status := cell.Status(cells)
+		switch face.State {
+		case header.FaceWon:
+			status = cell.Won
+		case header.FaceLost:
+			status = cell.Lost
+		}
+		if status != cell.Playing {
+			log.Printf("🏁 对局已结束: %s, 用时 %d 秒", status, session.Seconds)
+			session.End, session.Result = time.Now(), status.String()
+			if err := header.AppendSession("sessions.jsonl", session); err != nil {
+				log.Printf("⚠️ %v", err)
+			}
+			if !*restart {
+				break
+			}
+			session = header.Session{Client: profile.Name, Start: time.Now()}
+			history.Reset()
+			if faceFound {
+				click.Click(face.Center())
+			} else {
+				click.PressKey(click.VK_F2)
+			}
+			time.Sleep(500 * time.Millisecond)
+			continue
+		}
+
 		// 6. 求解阶段
 		start = time.Now()
 		solver := solver.NewSolver(cells)
 		safePoints, minePoints := solver.Solve()
+		wrongFlags := solver.WrongFlags()
+		if guess, ok := solver.LastGuess(); ok {
+			log.Printf("🎲 猜测 %v, 预测地雷概率 %.1f%%", guess.Point, guess.Probability*100)
+			pendingGuess, pendingStrategy = &guess, defaultStrategy
+		}
 		elapsed = time.Since(start)
 		log.Printf("🧮 求解耗时: %d ms", elapsed.Milliseconds())
 		total += elapsed
@@ -129,14 +384,73 @@ func main() {
 		// 7. 输出结果
 		fmt.Println("✅ 安全点:", safePoints)
 		fmt.Println("🚩 雷点:", minePoints)
+		if len(wrongFlags) > 0 {
+			log.Printf("❎ %d 面旗帜与数字矛盾，取消: %v", len(wrongFlags), wrongFlags)
+		}
+		decision := render.Decision{Safe: safePoints, Mines: minePoints, Unflag: wrongFlags}
+		if guess, ok := solver.LastGuess(); ok {
+			decision.Guess = &guess
+		}
+		// 与点击阶段的顺序一致：取消旗帜、左键、插旗
+		decision.Clicks = append(append(append(decision.Clicks, wrongFlags...), safePoints...), minePoints...)
 
-		// 8. 点击操作阶段
-		if len(safePoints) == 0 && len(minePoints) == 0 && i >= 6 {
-			log.Printf("🛑 未检测到新操作，退出循环")
+		// 新开局没有翻开的格子，交给下面的首次点击；否则对局仍在进行（包括"多条命"变体中踩雷之后），按最低风险猜测
+		stuck := len(safePoints) == 0 && len(minePoints) == 0 && len(wrongFlags) == 0 && countState(cells, cell.Empty) > 0
+		stop := false
+		if stuck {
+			guess, strategy, ok := stuckGuess(cells, minefield.MineCount)
+			switch {
+			case !ok:
+				log.Printf("🛑 未检测到新操作，退出循环")
+				stop = true
+			case strategy != "":
+				log.Printf("🎲 没有可推理的操作，猜测 %v, 预测地雷概率 %.1f%%", guess.Point, guess.Probability*100)
+				pendingGuess, pendingStrategy = &guess, strategy
+			default:
+				log.Printf("🎲 没有可推理的操作，猜测 %v", guess.Point)
+			}
+			if ok {
+				decision.Guess = &guess
+				decision.Clicks = []image.Point{guess.Point}
+			}
+		}
+
+		// 标注图在最终决策之后生成，包含本轮实际点击的格子
+		if *overlayDir != "" {
+			overlay := render.Overlay(mineFieldImg, horizontalLines, verticalLines, cells, decision)
+			path := filepath.Join(*overlayDir, fmt.Sprintf("%s_%03d.png", overlayPrefix, i+1))
+			saves.Add(1)
+			go func() {
+				defer saves.Done()
+				if err := kit.SaveImg(overlay, path); err != nil {
+					log.Printf("⚠️ 保存标注图失败: %v", err)
+				}
+			}()
+			log.Printf("🖼️ 标注图: %s", path)
+		}
+		if stop {
 			break
 		}
 
+		// 8. 点击操作阶段
+		if stuck {
+			p := decision.Guess.Point
+			click.Click(cells[p.Y][p.X].ScreenPos())
+			// 指针留在猜测的格子上会被当作遮挡，下一轮就无法看到猜测的结果
+			click.Move(mineFieldBounds.Min.Sub(image.Point{X: 8, Y: 8}))
+			continue
+		}
+
 		start = time.Now()
+		// 先取消插错的旗帜，它们可能同时是本轮的安全点
+		for _, point := range wrongFlags {
+			c := cells[point.Y][point.X]
+			for range clicker.RightClicksToUnflag(c.State) {
+				click.RightClick(c.ScreenPos())
+				time.Sleep(time.Millisecond * 20)
+			}
+		}
+
 		// 左键点击
 		for _, point := range safePoints {
 			p := cells[point.Y][point.X].ScreenPos()
@@ -144,16 +458,20 @@ func main() {
 			time.Sleep(time.Millisecond * 20)
 		}
 
-		// 右键点击
+		// 右键点击，"?" 格子需要多点一次才能变为旗帜
 		for _, point := range minePoints {
-			p := cells[point.Y][point.X].ScreenPos()
-			click.RightClick(p)
-			time.Sleep(time.Millisecond * 20)
+			c := cells[point.Y][point.X]
+			for range clicker.RightClicksToFlag(c.State) {
+				click.RightClick(c.ScreenPos())
+				time.Sleep(time.Millisecond * 20)
+			}
 		}
 
 		// 首次特殊点击
 		p := cells[len(cells)/2][len(cells[0])/2].ScreenPos()
 		click.Click(p)
+		// 把指针移出雷区，避免遮挡下一轮截图
+		click.Move(mineFieldBounds.Min.Sub(image.Point{X: 8, Y: 8}))
 		elapsed = time.Since(start)
 		log.Printf("🖱️ 操作耗时: %d ms", elapsed.Milliseconds())
 		total += elapsed
@@ -161,3 +479,131 @@ func main() {
 		log.Printf("📊 总耗时: %d ms", total.Milliseconds())
 	}
 }
+
+// calibrateLive 以当前截图为新开局标定主题，保留已有主题中的其他字段
+func calibrateLive(mineFieldImg image.Image, themePath string) {
+	calibration, err := theme.Calibrate(mineFieldImg, nil)
+	if err != nil {
+		log.Fatalf("标定失败: %v", err)
+	}
+	t := calibration.Theme
+	if old, err := theme.Load(themePath); err == nil {
+		old.BorderColor = t.BorderColor
+		old.CoveredColor = t.CoveredColor
+		old.CoveredRange = t.CoveredRange
+		old.BinarizeThreshold = t.BinarizeThreshold
+		old.CellSize = t.CellSize
+		t = old
+	}
+	if err := t.Save(themePath); err != nil {
+		log.Fatalf("保存主题失败: %v", err)
+	}
+	log.Printf("✅ 已标定 %dx%d 雷区并写入 %s", len(calibration.HorizontalLines)-1, len(calibration.VerticalLines)-1, themePath)
+}
+
+// captureHeader 截取雷区上方的信息栏
+func captureHeader(headerBounds image.Rectangle) (image.Image, error) {
+	if headerBounds.Empty() {
+		return nil, fmt.Errorf("雷区上方没有信息栏")
+	}
+	headerImg, err := screenshot.CaptureRect(headerBounds)
+	if err != nil {
+		return nil, fmt.Errorf("信息栏截图失败: %v", err)
+	}
+	return headerImg, nil
+}
+
+// countState 统计盘面中处于指定状态的格子数量
+func countState(cells [][]cell.GridCell, state cell.CellState) int {
+	count := 0
+	for _, row := range cells {
+		for _, c := range row {
+			if c.State == state {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// stuckGuess 求解器没有给出任何操作时的猜测：先用最低风险策略重新求解，
+// 边界上没有可猜的格子时翻开不与数字相邻、且周围未翻开格子最少（角、边优先）的格子，
+// 预测概率取剩余地雷密度。返回写入猜测日志的策略名，mineCount 未知（为 0）、预测概率无意义时为空
+func stuckGuess(cells [][]cell.GridCell, mineCount int) (solver.Guess, string, bool) {
+	config, _ := solver.ConfigByName("lowrisk")
+	guesser := solver.NewSolverWithConfig(cells, config)
+	guesser.Solve()
+	if guess, ok := guesser.LastGuess(); ok {
+		return guess, config.Name, true
+	}
+
+	covered, mines := 0, 0
+	for _, row := range cells {
+		for _, c := range row {
+			switch {
+			case c.State.Covered():
+				covered++
+			case c.State == cell.Flagged || c.State == cell.Mine:
+				mines++
+			}
+		}
+	}
+	best, bestCovered := image.Point{}, 9
+	for i, row := range cells {
+		for j, c := range row {
+			if !c.State.Covered() {
+				continue
+			}
+			constrained, coveredNeighbors := false, 0
+			for y := max(i-1, 0); y <= min(i+1, len(cells)-1); y++ {
+				for x := max(j-1, 0); x <= min(j+1, len(row)-1); x++ {
+					switch state := cells[y][x].State; {
+					case state >= cell.Number1 && state <= cell.Number8:
+						constrained = true
+					case state.Covered() && (y != i || x != j):
+						coveredNeighbors++
+					}
+				}
+			}
+			if !constrained && coveredNeighbors < bestCovered {
+				best, bestCovered = c.Position, coveredNeighbors
+			}
+		}
+	}
+	if bestCovered > 8 {
+		return solver.Guess{}, "", false
+	}
+	guess := solver.Guess{Point: best}
+	if mineCount == 0 {
+		return guess, "", true
+	}
+	guess.Probability = min(max(float64(mineCount-mines)/float64(covered), 0), 1)
+	return guess, "density", true
+}
+
+// resolveGuess 根据最新识别结果判断猜测格子是否为雷并写入猜测日志，仍未翻开时丢弃
+func resolveGuess(logger *calibration.Logger, guess solver.Guess, strategy string, cells [][]cell.GridCell) {
+	if logger == nil || guess.Point.Y >= len(cells) || guess.Point.X >= len(cells[0]) {
+		return
+	}
+	state := cells[guess.Point.Y][guess.Point.X].State
+	var mine bool
+	switch {
+	case state == cell.Mine || state == cell.Exploded:
+		mine = true
+	case state == cell.Empty || (state >= cell.Number1 && state <= cell.Number8):
+		mine = false
+	default:
+		return
+	}
+	if err := logger.Log(calibration.Record{
+		Source:    "live",
+		Strategy:  strategy,
+		Row:       guess.Point.Y,
+		Col:       guess.Point.X,
+		Predicted: guess.Probability,
+		Mine:      mine,
+	}); err != nil {
+		log.Printf("⚠️ %v", err)
+	}
+}
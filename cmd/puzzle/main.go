@@ -0,0 +1,36 @@
+package main
+
+import (
+	"flag"
+	"log"
+
+	"minego/internal/identify"
+	"minego/internal/puzzle"
+	"minego/internal/render"
+	"minego/pkg/kit"
+)
+
+// 生成唯一解的静态扫雷谜题，输出文本盘面与渲染后的 PNG
+func main() {
+	rows := flag.Int("rows", 9, "行数")
+	cols := flag.Int("cols", 9, "列数")
+	mines := flag.Int("mines", 10, "地雷数")
+	seed := flag.Uint64("seed", 1, "随机种子")
+	cellSize := flag.Int("cell", render.DefaultCellSize, "渲染单元格边长（像素）")
+	out := flag.String("out", "puzzle", "输出文件名前缀")
+	flag.Parse()
+
+	p, err := puzzle.Generate(*rows, *cols, *mines, *seed)
+	if err != nil {
+		log.Fatalf("生成谜题失败: %v", err)
+	}
+	log.Printf("🧩 谜题 %dx%d, 地雷 %d, 保留线索 %d 个", *rows, *cols, p.MineCount, p.Clues())
+
+	if err := identify.SaveResultToFile(p.Grid, *out+".txt"); err != nil {
+		log.Fatalf("保存谜题文本失败: %v", err)
+	}
+	if err := kit.SaveImg(render.Board(p.Grid, *cellSize), *out+".png"); err != nil {
+		log.Fatalf("保存谜题图片失败: %v", err)
+	}
+	log.Printf("💾 已保存 %s.txt 与 %s.png", *out, *out)
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"flag"
+	"image/png"
+	"log"
+	"os"
+
+	"minego/internal/degrade"
+	"minego/internal/identify"
+	"minego/internal/render"
+	"minego/pkg/imageproc"
+)
+
+// 从带标注的雷区截图（或渲染器）提取识别模板，写入模板目录
+func main() {
+	imagePath := flag.String("image", "", "雷区截图 PNG，为空时使用渲染器绘制的模板表")
+	labelsPath := flag.String("labels", "", "截图对应的文本盘面标注，格式同 GridcellRec.txt")
+	cellSize := flag.Int("cell", render.DefaultCellSize, "渲染单元格边长（像素，仅渲染模式）")
+	out := flag.String("out", "templates", "输出模板目录")
+	flag.Parse()
+
+	var templates *identify.TemplateClassifier
+	if *imagePath == "" {
+		templates = degrade.RenderedTemplates(*cellSize)
+	} else {
+		file, err := os.Open(*imagePath)
+		if err != nil {
+			log.Fatalf("打开截图失败: %v", err)
+		}
+		img, err := png.Decode(file)
+		file.Close()
+		if err != nil {
+			log.Fatalf("解码截图失败: %v", err)
+		}
+		labels, err := identify.LoadResultFromFile(*labelsPath)
+		if err != nil {
+			log.Fatalf("读取标注失败: %v", err)
+		}
+		if len(labels) == 0 || len(labels[0]) == 0 {
+			log.Fatalf("标注文件 %s 中没有格子", *labelsPath)
+		}
+		grid := imageproc.DetectMineSweeperGrid(img)
+		horizontalLines, verticalLines := grid.HorizontalLines, grid.VerticalLines
+		if len(horizontalLines)-1 != len(labels) || len(verticalLines)-1 != len(labels[0]) {
+			log.Fatalf("检测到 %dx%d 网格，与标注的 %dx%d 不一致",
+				len(horizontalLines)-1, len(verticalLines)-1, len(labels), len(labels[0]))
+		}
+		templates = identify.ExtractTemplates(img, horizontalLines, verticalLines, labels)
+	}
+
+	if err := templates.SaveTemplates(*out); err != nil {
+		log.Fatalf("保存模板失败: %v", err)
+	}
+	log.Printf("✅ 已写入 %d 个模板到 %s", len(templates.Templates), *out)
+}
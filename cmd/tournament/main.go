@@ -0,0 +1,74 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"strings"
+
+	"minego/internal/calibration"
+	"minego/internal/sim"
+	"minego/internal/solver"
+)
+
+// 在相同的种子盘面上对比多个求解器配置，输出配对统计
+func main() {
+	names := flag.String("configs", "first,lowrisk", "逗号分隔的求解器配置，可选: "+strings.Join(solver.ConfigNames(), ","))
+	rows := flag.Int("rows", 16, "行数")
+	cols := flag.Int("cols", 30, "列数")
+	mines := flag.Int("mines", 99, "地雷数")
+	wrongFlags := flag.Int("wrongflags", 0, "每局开局后插在非雷格上的旗帜数，模拟误插或误识别的旗帜，用于检验取消旗帜的流程")
+	games := flag.Int("games", 1000, "对局数")
+	seed := flag.Uint64("seed", 1, "起始种子")
+	examples := flag.Int("examples", 10, "每个方向最多列出的示例种子数")
+	guessLog := flag.String("guesslog", "", "将每次猜测的预测概率与结果追加写入该文件（JSON Lines）")
+	flag.Parse()
+
+	t := sim.Tournament{
+		Rows:            *rows,
+		Cols:            *cols,
+		Mines:           *mines,
+		WrongFlags:      *wrongFlags,
+		Games:           *games,
+		Seed:            *seed,
+		MaxExampleSeeds: *examples,
+	}
+	for _, name := range strings.Split(*names, ",") {
+		config, err := solver.ConfigByName(strings.TrimSpace(name))
+		if err != nil {
+			log.Fatalf("解析配置失败: %v", err)
+		}
+		t.Configs = append(t.Configs, config)
+	}
+	if len(t.Configs) < 2 {
+		log.Fatalf("至少需要两个求解器配置")
+	}
+
+	if *guessLog != "" {
+		logger, err := calibration.OpenLog(*guessLog)
+		if err != nil {
+			log.Fatalf("%v", err)
+		}
+		defer logger.Close()
+		t.OnGuess = func(config solver.Config, seed uint64, guess sim.GuessOutcome) {
+			rec := calibration.Record{
+				Source:    "sim",
+				Strategy:  config.Name,
+				Seed:      seed,
+				Row:       guess.Point.Y,
+				Col:       guess.Point.X,
+				Predicted: guess.Predicted,
+				Mine:      guess.Mine,
+			}
+			if guess.Random {
+				rec.Note = "random"
+			}
+			if err := logger.Log(rec); err != nil {
+				log.Printf("⚠️ %v", err)
+			}
+		}
+	}
+
+	log.Printf("🏆 开始锦标赛: %d 个配置 x %d 局", len(t.Configs), t.Games)
+	t.Run().WriteReport(os.Stdout)
+}
@@ -0,0 +1,88 @@
+// Package calibration 记录每次猜测的预测地雷概率与实际结果，并生成校准报告
+package calibration
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Record 一次猜测
+type Record struct {
+	Time      time.Time `json:"time"`
+	Source    string    `json:"source"`         // sim 或 live
+	Strategy  string    `json:"strategy"`       // 求解器配置名称
+	Seed      uint64    `json:"seed,omitempty"` // 模拟对局的种子
+	Row       int       `json:"row"`            // 猜测格子的行
+	Col       int       `json:"col"`            // 猜测格子的列
+	Predicted float64   `json:"predicted"`      // 预测的地雷概率
+	Mine      bool      `json:"mine"`           // 实际是否为雷
+	Note      string    `json:"note,omitempty"` // 附加说明，例如随机猜测
+}
+
+// Logger 以 JSON Lines 格式追加写入猜测记录，可并发使用
+type Logger struct {
+	mu   sync.Mutex
+	file *os.File
+	enc  *json.Encoder
+}
+
+// OpenLog 以追加模式打开日志文件
+func OpenLog(path string) (*Logger, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("打开猜测日志失败: %v", err)
+	}
+	return &Logger{file: file, enc: json.NewEncoder(file)}, nil
+}
+
+// Log 写入一条记录，未设置时间时使用当前时间
+func (l *Logger) Log(r Record) error {
+	if r.Time.IsZero() {
+		r.Time = time.Now()
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.enc.Encode(r); err != nil {
+		return fmt.Errorf("写入猜测日志失败: %v", err)
+	}
+	return nil
+}
+
+// Close 关闭日志文件
+func (l *Logger) Close() error {
+	return l.file.Close()
+}
+
+// Load 读取日志文件中的全部记录
+func Load(path string) ([]Record, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("打开猜测日志失败: %v", err)
+	}
+	defer file.Close()
+	return Read(file)
+}
+
+// Read 从 JSON Lines 流中读取记录
+func Read(r io.Reader) ([]Record, error) {
+	records := make([]Record, 0)
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var rec Record
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return nil, fmt.Errorf("解析第 %d 行失败: %v", line, err)
+		}
+		records = append(records, rec)
+	}
+	return records, scanner.Err()
+}
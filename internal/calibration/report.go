@@ -0,0 +1,183 @@
+package calibration
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"io"
+	"math"
+
+	"minego/internal/render"
+)
+
+// Bin 一个预测概率区间内的统计
+type Bin struct {
+	Lo, Hi        float64
+	Count         int
+	Mines         int
+	MeanPredicted float64 // 区间内预测概率的平均值
+}
+
+// Observed 区间内实际踩雷比例
+func (b Bin) Observed() float64 {
+	if b.Count == 0 {
+		return 0
+	}
+	return float64(b.Mines) / float64(b.Count)
+}
+
+// Bins 将记录按预测概率均匀分为 n 个区间，n 不为正时返回 nil
+func Bins(records []Record, n int) []Bin {
+	if n <= 0 {
+		return nil
+	}
+	bins := make([]Bin, n)
+	for i := range bins {
+		bins[i].Lo = float64(i) / float64(n)
+		bins[i].Hi = float64(i+1) / float64(n)
+	}
+	for _, r := range records {
+		i := min(max(int(r.Predicted*float64(n)), 0), n-1)
+		bins[i].Count++
+		bins[i].MeanPredicted += r.Predicted
+		if r.Mine {
+			bins[i].Mines++
+		}
+	}
+	for i := range bins {
+		if bins[i].Count > 0 {
+			bins[i].MeanPredicted /= float64(bins[i].Count)
+		}
+	}
+	return bins
+}
+
+// ExpectedCalibrationError 按样本数加权的 |预测 - 实际| 平均值
+func ExpectedCalibrationError(bins []Bin) float64 {
+	total, sum := 0, 0.0
+	for _, b := range bins {
+		total += b.Count
+		sum += float64(b.Count) * math.Abs(b.MeanPredicted-b.Observed())
+	}
+	if total == 0 {
+		return 0
+	}
+	return sum / float64(total)
+}
+
+// WriteTable 输出校准表
+func WriteTable(w io.Writer, bins []Bin) {
+	fmt.Fprintf(w, "%-13s %8s %8s %10s %10s\n", "预测区间", "样本", "踩雷", "平均预测", "实际比例")
+	for _, b := range bins {
+		if b.Count == 0 {
+			fmt.Fprintf(w, "[%.2f, %.2f) %8d %8s %10s %10s\n", b.Lo, b.Hi, 0, "-", "-", "-")
+			continue
+		}
+		fmt.Fprintf(w, "[%.2f, %.2f) %8d %8d %9.1f%% %9.1f%%\n",
+			b.Lo, b.Hi, b.Count, b.Mines, b.MeanPredicted*100, b.Observed()*100)
+	}
+	fmt.Fprintf(w, "ECE = %.2f%%\n", ExpectedCalibrationError(bins)*100)
+}
+
+var (
+	chartBackground = color.RGBA{255, 255, 255, 255}
+	chartAxis       = color.RGBA{40, 40, 40, 255}
+	chartGrid       = color.RGBA{225, 225, 225, 255}
+	chartDiagonal   = color.RGBA{160, 160, 160, 255}
+	chartBar        = color.RGBA{120, 160, 220, 255}
+	chartPoint      = color.RGBA{200, 40, 40, 255}
+)
+
+// Chart 绘制可靠性图：横轴为预测概率，纵轴为实际踩雷比例，
+// 灰色对角线表示完美校准，蓝色柱为各区间实际比例，红点为 (平均预测, 实际比例)，点的大小反映样本数
+func Chart(bins []Bin, size int) *image.RGBA {
+	const margin = 40
+	img := image.NewRGBA(image.Rect(0, 0, size+2*margin, size+2*margin))
+	draw.Draw(img, img.Bounds(), image.NewUniform(chartBackground), image.Point{}, draw.Src)
+
+	// 坐标换算：概率 (px, py) -> 像素
+	toPixel := func(px, py float64) image.Point {
+		return image.Point{X: margin + int(px*float64(size)), Y: margin + size - int(py*float64(size))}
+	}
+
+	for k := 0; k <= 10; k++ {
+		v := float64(k) / 10
+		a, b := toPixel(v, 0), toPixel(v, 1)
+		line(img, a, b, chartGrid)
+		a, b = toPixel(0, v), toPixel(1, v)
+		line(img, a, b, chartGrid)
+	}
+
+	maxCount := 1
+	for _, b := range bins {
+		maxCount = max(maxCount, b.Count)
+	}
+	for _, b := range bins {
+		if b.Count == 0 {
+			continue
+		}
+		top := toPixel(b.Lo, b.Observed())
+		bottom := toPixel(b.Hi, 0)
+		draw.Draw(img, image.Rect(top.X+1, top.Y, bottom.X-1, bottom.Y), image.NewUniform(chartBar), image.Point{}, draw.Src)
+	}
+
+	line(img, toPixel(0, 0), toPixel(1, 1), chartDiagonal)
+	line(img, toPixel(0, 0), toPixel(1, 0), chartAxis)
+	line(img, toPixel(0, 0), toPixel(0, 1), chartAxis)
+
+	for _, b := range bins {
+		if b.Count == 0 {
+			continue
+		}
+		center := toPixel(b.MeanPredicted, b.Observed())
+		r := 2 + int(6*math.Sqrt(float64(b.Count)/float64(maxCount)))
+		draw.Draw(img, image.Rect(center.X-r, center.Y-r, center.X+r+1, center.Y+r+1), image.NewUniform(chartPoint), image.Point{}, draw.Src)
+	}
+
+	// 刻度标签
+	for k := 0; k <= 10; k += 5 {
+		v := float64(k) / 10
+		label := fmt.Sprintf("%.1f", v)
+		p := toPixel(v, 0)
+		render.DrawTextCentered(img, image.Point{X: p.X, Y: p.Y + 14}, label, 1, chartAxis)
+		p = toPixel(0, v)
+		render.DrawTextCentered(img, image.Point{X: p.X - 18, Y: p.Y}, label, 1, chartAxis)
+	}
+	return img
+}
+
+// line 用 Bresenham 算法画直线
+func line(img *image.RGBA, a, b image.Point, c color.Color) {
+	dx, dy := abs(b.X-a.X), -abs(b.Y-a.Y)
+	sx, sy := 1, 1
+	if a.X > b.X {
+		sx = -1
+	}
+	if a.Y > b.Y {
+		sy = -1
+	}
+	err := dx + dy
+	for {
+		img.Set(a.X, a.Y, c)
+		if a == b {
+			return
+		}
+		e2 := 2 * err
+		if e2 >= dy {
+			err += dy
+			a.X += sx
+		}
+		if e2 <= dx {
+			err += dx
+			a.Y += sy
+		}
+	}
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
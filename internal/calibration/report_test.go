@@ -0,0 +1,65 @@
+package calibration_test
+
+import (
+	"math"
+	"testing"
+
+	"minego/internal/calibration"
+)
+
+func TestBins(t *testing.T) {
+	records := []calibration.Record{
+		{Predicted: 0.1, Mine: false},
+		{Predicted: 0.2, Mine: true},
+		{Predicted: 0.6, Mine: true},
+		{Predicted: 1.0, Mine: true}, // 概率 1 落在最后一个区间
+		{Predicted: -0.1, Mine: false},
+	}
+	bins := calibration.Bins(records, 2)
+	if len(bins) != 2 {
+		t.Fatalf("区间数 %d, want 2", len(bins))
+	}
+	want := []calibration.Bin{
+		{Lo: 0, Hi: 0.5, Count: 3, Mines: 1, MeanPredicted: 0.2 / 3},
+		{Lo: 0.5, Hi: 1, Count: 2, Mines: 2, MeanPredicted: 0.8},
+	}
+	for i, b := range bins {
+		w := want[i]
+		if b.Lo != w.Lo || b.Hi != w.Hi || b.Count != w.Count || b.Mines != w.Mines || math.Abs(b.MeanPredicted-w.MeanPredicted) > 1e-9 {
+			t.Errorf("区间 %d = %+v, want %+v", i, b, w)
+		}
+	}
+	for _, n := range []int{0, -1} {
+		if got := calibration.Bins(records, n); got != nil {
+			t.Errorf("Bins(%d) = %v, want nil", n, got)
+		}
+	}
+}
+
+func TestExpectedCalibrationError(t *testing.T) {
+	tests := []struct {
+		name string
+		bins []calibration.Bin
+		want float64
+	}{
+		{name: "无样本", bins: nil, want: 0},
+		{name: "空区间", bins: []calibration.Bin{{Lo: 0, Hi: 1}}, want: 0},
+		{name: "完美校准", bins: []calibration.Bin{{Count: 4, Mines: 1, MeanPredicted: 0.25}}, want: 0},
+		{
+			// (10*|0.1-0.3| + 30*|0.7-0.5|) / 40
+			name: "按样本数加权",
+			bins: []calibration.Bin{
+				{Count: 10, Mines: 3, MeanPredicted: 0.1},
+				{Count: 30, Mines: 15, MeanPredicted: 0.7},
+			},
+			want: 0.2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := calibration.ExpectedCalibrationError(tt.bins); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("ECE = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -8,7 +8,11 @@ import (
 type CellState int
 
 const (
-	Mine CellState = iota - 4
+	Unobserved   CellState = iota - 8 // 被其他窗口、提示框或鼠标指针遮挡，或与任何已知状态都不相符，状态未知
+	QuestionMark                      // 右键循环出的 "?" 标记，仍未翻开
+	Exploded                          // 踩中的地雷（红色背景），游戏失败时出现
+	Misflag                           // 插错的旗帜，游戏失败时显示
+	Mine                              // 翻开的地雷
 	Flagged
 	Unknown
 	Locked
@@ -23,6 +27,11 @@ const (
 	Number8
 )
 
+// Covered 是否为未翻开且未插旗的格子（求解时的未知格）
+func (s CellState) Covered() bool {
+	return s == Unknown || s == QuestionMark
+}
+
 type MineField struct {
 	Bounds image.Rectangle
 	Grid   [][]GridCell
@@ -34,7 +43,8 @@ type GridCell struct {
 	X, Y         int // 坐标位置
 	Width, Hight int
 	Position     image.Point
-	Color        color.Color
+	Color        color.RGBA // 中心像素的颜色
+	Confidence   float64    // 识别置信度，0 到 1
 }
 
 func NewMineField(bounds image.Rectangle, cells [][]GridCell) *MineField {
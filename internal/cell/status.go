@@ -0,0 +1,41 @@
+package cell
+
+// GameStatus 从盘面推断的对局状态
+type GameStatus int
+
+const (
+	Playing GameStatus = iota
+	Lost
+	Won
+)
+
+func (s GameStatus) String() string {
+	switch s {
+	case Lost:
+		return "失败"
+	case Won:
+		return "胜利"
+	default:
+		return "进行中"
+	}
+}
+
+// Status 仅根据盘面判断对局是否结束：出现踩中的地雷或插错的旗帜即为失败；
+// 没有未翻开（含无法观测）的格子即为胜利。"多条命"变体中翻开的地雷（Mine）不代表失败
+func Status(grid [][]GridCell) GameStatus {
+	unknown := 0
+	for _, row := range grid {
+		for _, c := range row {
+			switch c.State {
+			case Exploded, Misflag:
+				return Lost
+			case Unknown, QuestionMark, Unobserved:
+				unknown++
+			}
+		}
+	}
+	if unknown == 0 && len(grid) > 0 {
+		return Won
+	}
+	return Playing
+}
@@ -5,6 +5,7 @@ import (
 	"image"
 	"time"
 
+	"minego/internal/cell"
 	"minego/pkg/winapi/click"
 )
 
@@ -59,6 +60,28 @@ func (c *Clicker) Start() {
 	}()
 }
 
+// RightClicksToFlag 返回把处于 state 的格子插上旗帜所需的右键次数。
+// 开启标记后右键在 未翻开 → 旗帜 → "?" → 未翻开 之间循环，"?" 需要多点一次回到未翻开
+func RightClicksToFlag(state cell.CellState) int {
+	switch state {
+	case cell.Unknown:
+		return 1
+	case cell.QuestionMark:
+		return 2
+	default:
+		return 0
+	}
+}
+
+// RightClicksToUnflag 返回取消处于 state 的格子上的旗帜所需的右键次数。
+// 开启标记时右键一次得到 "?"，与未翻开一样可以直接左键翻开，因此只需一次
+func RightClicksToUnflag(state cell.CellState) int {
+	if state == cell.Flagged {
+		return 1
+	}
+	return 0
+}
+
 // Stop 停止点击协程
 func (c *Clicker) Stop() {
 	close(c.taskChan)
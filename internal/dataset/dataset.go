@@ -0,0 +1,264 @@
+// Package dataset 将每次识别的单元格截图导出为按状态分目录的数据集，
+// 用于人工校对标注、重建识别模板以及回归测试。
+//
+// 目录结构：
+//
+//	<dir>/manifest.jsonl      每个单元格一条记录：预测状态、置信度、来源截图与坐标
+//	<dir>/screens/<时间>.png  来源截图
+//	<dir>/<状态>/<时间>_rRR_cCC.png  单元格图像，所在目录即当前标注
+//
+// 校对时把文件移动到正确状态的目录即可（或使用 Relabel），清单中的预测结果保持不变
+package dataset
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"minego/internal/cell"
+	"minego/internal/identify"
+)
+
+const (
+	ManifestFile = "manifest.jsonl"
+	screensDir   = "screens"
+	stampFormat  = "20060102-150405.000"
+)
+
+// Entry 清单中的一条记录
+type Entry struct {
+	File       string    `json:"file"`      // 导出时的相对路径
+	Predicted  string    `json:"predicted"` // 识别结果，取值同模板文件名前缀
+	Confidence float64   `json:"confidence"`
+	Source     string    `json:"source"` // 来源截图的相对路径
+	Row        int       `json:"row"`
+	Col        int       `json:"col"`
+	X          int       `json:"x"` // 单元格中心在来源截图中的坐标
+	Y          int       `json:"y"`
+	Width      int       `json:"width"`
+	Hight      int       `json:"hight"`
+	Time       time.Time `json:"time"`
+}
+
+// Writer 向数据集目录追加导出结果，可并发使用
+type Writer struct {
+	dir      string
+	mu       sync.Mutex
+	manifest *os.File
+	enc      *json.Encoder
+}
+
+// Open 打开（必要时创建）数据集目录
+func Open(dir string) (*Writer, error) {
+	if err := os.MkdirAll(filepath.Join(dir, screensDir), 0o755); err != nil {
+		return nil, fmt.Errorf("创建数据集目录失败: %v", err)
+	}
+	file, err := os.OpenFile(filepath.Join(dir, ManifestFile), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("打开数据集清单失败: %v", err)
+	}
+	return &Writer{dir: dir, manifest: file, enc: json.NewEncoder(file)}, nil
+}
+
+// Export 保存来源截图及其中每个单元格的图像，返回导出的单元格数
+func (w *Writer) Export(img image.Image, cells [][]cell.GridCell) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	now := time.Now()
+	stamp := now.Format(stampFormat)
+	source := filepath.Join(screensDir, stamp+".png")
+	if err := savePNG(filepath.Join(w.dir, source), img); err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, row := range cells {
+		for _, c := range row {
+			state := identify.StateFileName(c.State)
+			file := filepath.Join(state, fmt.Sprintf("%s_r%02d_c%02d.png", stamp, c.Position.Y, c.Position.X))
+			if err := os.MkdirAll(filepath.Join(w.dir, state), 0o755); err != nil {
+				return count, fmt.Errorf("创建状态目录失败: %v", err)
+			}
+			if err := savePNG(filepath.Join(w.dir, file), identify.CellCrop(img, c.X, c.Y, c.Width, c.Hight)); err != nil {
+				return count, err
+			}
+			if err := w.enc.Encode(Entry{
+				File:       filepath.ToSlash(file),
+				Predicted:  state,
+				Confidence: c.Confidence,
+				Source:     filepath.ToSlash(source),
+				Row:        c.Position.Y,
+				Col:        c.Position.X,
+				X:          c.X,
+				Y:          c.Y,
+				Width:      c.Width,
+				Hight:      c.Hight,
+				Time:       now,
+			}); err != nil {
+				return count, fmt.Errorf("写入数据集清单失败: %v", err)
+			}
+			count++
+		}
+	}
+	return count, nil
+}
+
+// Close 关闭清单文件
+func (w *Writer) Close() error {
+	return w.manifest.Close()
+}
+
+// Sample 数据集中的一个单元格图像
+type Sample struct {
+	Entry                // 清单中的记录，清单缺失时只有 File
+	Label cell.CellState // 当前标注，即文件所在的状态目录
+	Path  string         // 文件的实际路径
+}
+
+// Corrected 标注是否与识别结果不同
+func (s Sample) Corrected() bool {
+	return s.Predicted != identify.StateFileName(s.Label)
+}
+
+// Load 扫描各状态目录并关联清单记录，按文件名排序返回
+func Load(dir string) ([]Sample, error) {
+	entries, err := readManifest(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		return nil, err
+	}
+
+	dirs, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("读取数据集目录失败: %v", err)
+	}
+	samples := make([]Sample, 0)
+	for _, d := range dirs {
+		state, ok := identify.StateFromFileName(d.Name())
+		if !d.IsDir() || !ok {
+			continue
+		}
+		files, err := filepath.Glob(filepath.Join(dir, d.Name(), "*.png"))
+		if err != nil {
+			return nil, fmt.Errorf("读取状态目录失败: %v", err)
+		}
+		for _, file := range files {
+			entry, ok := entries[filepath.Base(file)]
+			if !ok {
+				entry = Entry{File: filepath.ToSlash(filepath.Join(d.Name(), filepath.Base(file)))}
+			}
+			samples = append(samples, Sample{Entry: entry, Label: state, Path: file})
+		}
+	}
+	sort.Slice(samples, func(a, b int) bool {
+		return filepath.Base(samples[a].Path) < filepath.Base(samples[b].Path)
+	})
+	return samples, nil
+}
+
+// Relabel 将文件名为 name 的单元格图像移动到 state 对应的目录
+func Relabel(dir, name string, state cell.CellState) error {
+	samples, err := Load(dir)
+	if err != nil {
+		return err
+	}
+	name = filepath.Base(name)
+	for _, s := range samples {
+		if filepath.Base(s.Path) != name {
+			continue
+		}
+		target := filepath.Join(dir, identify.StateFileName(state))
+		if err := os.MkdirAll(target, 0o755); err != nil {
+			return fmt.Errorf("创建状态目录失败: %v", err)
+		}
+		if err := os.Rename(s.Path, filepath.Join(target, name)); err != nil {
+			return fmt.Errorf("移动 %s 失败: %v", name, err)
+		}
+		return nil
+	}
+	return fmt.Errorf("数据集中没有 %s", name)
+}
+
+// Templates 由数据集重建模板识别器，每种状态最多保留 perState 个外观差异最大的样本。
+// 标为无法观测的样本没有固定外观，不作为模板
+func Templates(samples []Sample, perState int) (*identify.TemplateClassifier, error) {
+	all := identify.NewTemplateClassifier()
+	for _, s := range samples {
+		if s.Label == cell.Unobserved {
+			continue
+		}
+		img, err := LoadPNG(s.Path)
+		if err != nil {
+			return nil, err
+		}
+		all.Add(s.Label, img)
+	}
+	if len(all.Templates) == 0 {
+		return nil, fmt.Errorf("数据集中没有样本")
+	}
+	return all.Diverse(perState), nil
+}
+
+// readManifest 读取清单，按文件名索引；清单不存在时返回空索引
+func readManifest(path string) (map[string]Entry, error) {
+	entries := make(map[string]Entry)
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return entries, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("打开数据集清单失败: %v", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("解析数据集清单第 %d 行失败: %v", line, err)
+		}
+		entries[filepath.Base(e.File)] = e
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取数据集清单失败: %v", err)
+	}
+	return entries, nil
+}
+
+func savePNG(path string, img image.Image) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("创建 %s 失败: %v", path, err)
+	}
+	defer file.Close()
+	if err := png.Encode(file, img); err != nil {
+		return fmt.Errorf("写入 %s 失败: %v", path, err)
+	}
+	return nil
+}
+
+// LoadPNG 读取样本或来源截图
+func LoadPNG(path string) (image.Image, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("打开 %s 失败: %v", path, err)
+	}
+	defer file.Close()
+	img, err := png.Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("解码 %s 失败: %v", path, err)
+	}
+	return img, nil
+}
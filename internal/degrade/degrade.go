@@ -0,0 +1,213 @@
+// Package degrade 对雷区图像施加可控的退化（缩放、模糊、伽马、偏色、JPEG、噪声），
+// 用于压力测试识别流程在不同显示条件下的准确率
+package degrade
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"math"
+	"math/rand/v2"
+)
+
+// Degradation 一种图像退化
+type Degradation struct {
+	Name  string
+	Scale float64 // 几何缩放比例，识别时网格线坐标按此比例换算
+	Apply func(img image.Image) *image.RGBA
+}
+
+// Standard 返回默认的退化组合
+func Standard() []Degradation {
+	return []Degradation{
+		Identity(),
+		Rescale(1.25),
+		Rescale(1.5),
+		Blur(1),
+		Gamma(0.8),
+		Gamma(1.25),
+		ColorShift(8, -6, 10),
+		JPEG(85),
+		JPEG(60),
+		Noise(6, 1),
+	}
+}
+
+// Identity 不做任何处理，作为对照组
+func Identity() Degradation {
+	return Degradation{Name: "原图", Scale: 1, Apply: toRGBA}
+}
+
+// Rescale 双线性缩放，模拟 125%/150% 等显示缩放
+func Rescale(factor float64) Degradation {
+	return Degradation{
+		Name:  fmt.Sprintf("缩放%.0f%%", factor*100),
+		Scale: factor,
+		Apply: func(img image.Image) *image.RGBA {
+			src := toRGBA(img)
+			sb := src.Bounds()
+			w := int(math.Round(float64(sb.Dx()) * factor))
+			h := int(math.Round(float64(sb.Dy()) * factor))
+			dst := image.NewRGBA(image.Rect(0, 0, w, h))
+			for y := range h {
+				fy := math.Max((float64(y)+0.5)/factor-0.5, 0)
+				y0 := min(int(fy), sb.Dy()-1)
+				y1 := min(y0+1, sb.Dy()-1)
+				wy := fy - float64(y0)
+				for x := range w {
+					fx := math.Max((float64(x)+0.5)/factor-0.5, 0)
+					x0 := min(int(fx), sb.Dx()-1)
+					x1 := min(x0+1, sb.Dx()-1)
+					wx := fx - float64(x0)
+					for c := range 3 {
+						p00 := float64(src.Pix[src.PixOffset(sb.Min.X+x0, sb.Min.Y+y0)+c])
+						p01 := float64(src.Pix[src.PixOffset(sb.Min.X+x1, sb.Min.Y+y0)+c])
+						p10 := float64(src.Pix[src.PixOffset(sb.Min.X+x0, sb.Min.Y+y1)+c])
+						p11 := float64(src.Pix[src.PixOffset(sb.Min.X+x1, sb.Min.Y+y1)+c])
+						v := (p00*(1-wx)+p01*wx)*(1-wy) + (p10*(1-wx)+p11*wx)*wy
+						dst.Pix[dst.PixOffset(x, y)+c] = clamp(v)
+					}
+					dst.Pix[dst.PixOffset(x, y)+3] = 255
+				}
+			}
+			return dst
+		},
+	}
+}
+
+// Blur 可分离的盒式模糊，模拟 ClearType 类平滑
+func Blur(radius int) Degradation {
+	return Degradation{
+		Name:  fmt.Sprintf("模糊r%d", radius),
+		Scale: 1,
+		Apply: func(img image.Image) *image.RGBA {
+			src := toRGBA(img)
+			tmp := boxBlur(src, radius, 1, 0)
+			return boxBlur(tmp, radius, 0, 1)
+		},
+	}
+}
+
+// Gamma 伽马校正，模拟不同的显示器颜色配置
+func Gamma(gamma float64) Degradation {
+	var table [256]uint8
+	for i := range table {
+		table[i] = clamp(255 * math.Pow(float64(i)/255, gamma))
+	}
+	return Degradation{
+		Name:  fmt.Sprintf("伽马%.2f", gamma),
+		Scale: 1,
+		Apply: func(img image.Image) *image.RGBA {
+			return mapChannels(img, func(c int, v uint8) uint8 { return table[v] })
+		},
+	}
+}
+
+// ColorShift 对 RGB 三个通道分别加上偏移量
+func ColorShift(dr, dg, db int) Degradation {
+	offsets := [3]int{dr, dg, db}
+	return Degradation{
+		Name:  fmt.Sprintf("偏色%+d%+d%+d", dr, dg, db),
+		Scale: 1,
+		Apply: func(img image.Image) *image.RGBA {
+			return mapChannels(img, func(c int, v uint8) uint8 { return clamp(float64(int(v) + offsets[c])) })
+		},
+	}
+}
+
+// JPEG 以指定质量编码再解码
+func JPEG(quality int) Degradation {
+	return Degradation{
+		Name:  fmt.Sprintf("JPEG%d", quality),
+		Scale: 1,
+		Apply: func(img image.Image) *image.RGBA {
+			var buf bytes.Buffer
+			if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+				return toRGBA(img)
+			}
+			decoded, err := jpeg.Decode(&buf)
+			if err != nil {
+				return toRGBA(img)
+			}
+			return toRGBA(decoded)
+		},
+	}
+}
+
+// Noise 添加高斯噪声，seed 相同则噪声相同
+func Noise(sigma float64, seed uint64) Degradation {
+	return Degradation{
+		Name:  fmt.Sprintf("噪声σ%.0f", sigma),
+		Scale: 1,
+		Apply: func(img image.Image) *image.RGBA {
+			rng := rand.New(rand.NewPCG(seed, seed))
+			return mapChannels(img, func(c int, v uint8) uint8 {
+				return clamp(float64(v) + rng.NormFloat64()*sigma)
+			})
+		},
+	}
+}
+
+// ScaleLines 按退化的缩放比例换算网格线坐标
+func (d Degradation) ScaleLines(lines []int) []int {
+	scaled := make([]int, len(lines))
+	for i, l := range lines {
+		scaled[i] = int(math.Round(float64(l) * d.Scale))
+	}
+	return scaled
+}
+
+// toRGBA 复制为以 (0,0) 为原点的 *image.RGBA
+func toRGBA(img image.Image) *image.RGBA {
+	b := img.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), img, b.Min, draw.Src)
+	return dst
+}
+
+// mapChannels 逐像素逐通道变换 RGB，alpha 固定为 255
+func mapChannels(img image.Image, fn func(c int, v uint8) uint8) *image.RGBA {
+	dst := toRGBA(img)
+	for i := 0; i < len(dst.Pix); i += 4 {
+		for c := range 3 {
+			dst.Pix[i+c] = fn(c, dst.Pix[i+c])
+		}
+		dst.Pix[i+3] = 255
+	}
+	return dst
+}
+
+// boxBlur 沿 (dx, dy) 方向做一维盒式模糊
+func boxBlur(src *image.RGBA, radius, dx, dy int) *image.RGBA {
+	b := src.Bounds()
+	dst := image.NewRGBA(b)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			var sum [3]int
+			n := 0
+			for k := -radius; k <= radius; k++ {
+				p := image.Point{X: x + k*dx, Y: y + k*dy}
+				if !p.In(b) {
+					continue
+				}
+				off := src.PixOffset(p.X, p.Y)
+				for c := range 3 {
+					sum[c] += int(src.Pix[off+c])
+				}
+				n++
+			}
+			off := dst.PixOffset(x, y)
+			for c := range 3 {
+				dst.Pix[off+c] = uint8(sum[c] / n)
+			}
+			dst.Pix[off+3] = 255
+		}
+	}
+	return dst
+}
+
+func clamp(v float64) uint8 {
+	return uint8(math.Max(0, math.Min(255, math.Round(v))))
+}
@@ -0,0 +1,323 @@
+package degrade
+
+import (
+	"fmt"
+	"image"
+	"io"
+	"math/rand/v2"
+	"path/filepath"
+	"sort"
+
+	"minego/internal/cell"
+	"minego/internal/dataset"
+	"minego/internal/identify"
+	"minego/internal/imgpos"
+	"minego/internal/render"
+	"minego/pkg/imageproc"
+)
+
+// Accuracy 某一状态的识别统计
+type Accuracy struct {
+	Correct int
+	Total   int
+}
+
+// Rate 返回识别准确率，没有样本时返回 1
+func (a Accuracy) Rate() float64 {
+	if a.Total == 0 {
+		return 1
+	}
+	return float64(a.Correct) / float64(a.Total)
+}
+
+// Report 单个退化下的识别结果
+type Report struct {
+	Name    string
+	ByState map[cell.CellState]*Accuracy
+	Overall Accuracy
+}
+
+// SampleBoard 按种子生成一个包含覆盖格、旗帜、翻开的地雷、空白与各数字的盘面，作为识别的真值。
+// 随机布局几乎不会出现 6 到 8，也不会出现 "?"、踩中的地雷、插错的旗帜与无法观测的格子，
+// 盘面不小于 3x3 且有地雷时各放置至少一个，因此地雷总数可能与 mineCount 略有出入
+func SampleBoard(rows, cols, mineCount int, seed uint64) [][]cell.GridCell {
+	rng := rand.New(rand.NewPCG(seed, seed))
+	mines := make([][]bool, rows)
+	for i := range mines {
+		mines[i] = make([]bool, cols)
+	}
+	for _, k := range rng.Perm(rows * cols)[:min(mineCount, rows*cols)] {
+		mines[k/cols][k%cols] = true
+	}
+	seeded := make(map[image.Point]cell.CellState)
+	if rows >= 3 && cols >= 3 && mineCount > 0 {
+		seeded = seedRareStates(rng, mines)
+	}
+
+	grid := make([][]cell.GridCell, rows)
+	for i := range grid {
+		grid[i] = make([]cell.GridCell, cols)
+		for j := range grid[i] {
+			grid[i][j].Position = image.Point{X: j, Y: i}
+			switch r := rng.IntN(4); {
+			case seeded[grid[i][j].Position] != 0:
+				grid[i][j].State = seeded[grid[i][j].Position]
+			case mines[i][j] && r < 2:
+				grid[i][j].State = cell.Flagged
+			case mines[i][j] && r == 2:
+				grid[i][j].State = cell.Mine
+			case mines[i][j] || r == 3:
+				grid[i][j].State = cell.Unknown
+			default:
+				grid[i][j].State = cell.Empty + cell.CellState(countMines(mines, i, j))
+			}
+		}
+	}
+	return grid
+}
+
+// seedRareStates 在互不重叠的 3x3 区域中心放置 6、7、8（按数字重新布置周围的地雷），
+// 再在其余位置各放置一个 "?"、踩中的地雷、插错的旗帜与无法观测的格子，返回这些格子的状态
+func seedRareStates(rng *rand.Rand, mines [][]bool) map[image.Point]cell.CellState {
+	rows, cols := len(mines), len(mines[0])
+	seeded := make(map[image.Point]cell.CellState)
+	reserved := make(map[image.Point]bool)
+	order := rng.Perm(rows * cols)
+
+	for n := cell.Number6; n <= cell.Number8; n++ {
+		for _, k := range order {
+			center := image.Point{X: k % cols, Y: k / cols}
+			if center.X == 0 || center.Y == 0 || center.X == cols-1 || center.Y == rows-1 {
+				continue
+			}
+			neighbors := make([]image.Point, 0, 8)
+			free := !reserved[center]
+			for dy := -1; dy <= 1 && free; dy++ {
+				for dx := -1; dx <= 1; dx++ {
+					p := center.Add(image.Point{X: dx, Y: dy})
+					if reserved[p] {
+						free = false
+						break
+					}
+					if p != center {
+						neighbors = append(neighbors, p)
+					}
+				}
+			}
+			if !free {
+				continue
+			}
+			mines[center.Y][center.X] = false
+			for m, idx := range rng.Perm(len(neighbors)) {
+				p := neighbors[idx]
+				mines[p.Y][p.X] = m < int(n-cell.Empty)
+				reserved[p] = true
+			}
+			reserved[center] = true
+			seeded[center] = n
+			break
+		}
+	}
+
+	for _, state := range []cell.CellState{cell.QuestionMark, cell.Exploded, cell.Misflag, cell.Unobserved} {
+		for _, k := range order {
+			p := image.Point{X: k % cols, Y: k / cols}
+			if reserved[p] {
+				continue
+			}
+			switch state {
+			case cell.Exploded:
+				mines[p.Y][p.X] = true
+			case cell.Misflag:
+				mines[p.Y][p.X] = false
+			}
+			reserved[p] = true
+			seeded[p] = state
+			break
+		}
+	}
+	return seeded
+}
+
+// Evaluate 使用 identify.DefaultRecognizer 评估识别准确率
+func Evaluate(truth [][]cell.GridCell, cellSize int, degradations []Degradation) []Report {
+	return EvaluateWith(identify.DefaultRecognizer, truth, cellSize, degradations)
+}
+
+// EvaluateWith 渲染真值盘面，依次施加各退化后用指定识别器识别，统计每种状态的准确率
+// 网格线直接由渲染参数换算，因此结果只反映单元格识别本身
+func EvaluateWith(recognizer identify.Recognizer, truth [][]cell.GridCell, cellSize int, degradations []Degradation) []Report {
+	img := render.Board(truth, cellSize)
+	horizontalLines, verticalLines := render.Lines(len(truth), len(truth[0]), cellSize)
+
+	reports := make([]Report, 0, len(degradations))
+	for _, d := range degradations {
+		degraded := d.Apply(img)
+		result := identify.IdentifyWith(
+			recognizer,
+			imgpos.NewImageWithOffset(degraded, image.Point{}),
+			d.ScaleLines(horizontalLines),
+			d.ScaleLines(verticalLines),
+		)
+
+		report := Report{Name: d.Name, ByState: make(map[cell.CellState]*Accuracy)}
+		for i, row := range truth {
+			for j, c := range row {
+				report.add(c.State, result[i][j].State)
+			}
+		}
+		reports = append(reports, report)
+	}
+	return reports
+}
+
+// EvaluateCapture 用指定识别器识别真实截图，与标注盘面（格式同 GridcellRec.txt）逐格比较。
+// 网格线从截图中检测，行列数须与标注一致
+func EvaluateCapture(recognizer identify.Recognizer, name string, img image.Image, labels [][]cell.GridCell) (Report, error) {
+	if len(labels) == 0 || len(labels[0]) == 0 {
+		return Report{}, fmt.Errorf("标注盘面为空")
+	}
+	horizontalLines, verticalLines, err := captureGrid(img, len(labels), len(labels[0]))
+	if err != nil {
+		return Report{}, err
+	}
+	result := identify.IdentifyWith(recognizer, imgpos.NewImageWithOffset(img, image.Point{}), horizontalLines, verticalLines)
+
+	report := Report{Name: name, ByState: make(map[cell.CellState]*Accuracy)}
+	for i, row := range labels {
+		for j, c := range row {
+			report.add(c.State, result[i][j].State)
+		}
+	}
+	return report, nil
+}
+
+// captureGrid 依次按周期与各阈值方式检测网格，返回第一个与标注行列数一致的结果
+func captureGrid(img image.Image, rows, cols int) ([]int, []int, error) {
+	matches := func(h, v []int) bool { return len(h)-1 == rows && len(v)-1 == cols }
+	if h, v, err := imageproc.DetectGridByPeriod(img); err == nil && matches(h, v) {
+		return h, v, nil
+	}
+	for _, mode := range []imageproc.ThresholdMode{imageproc.ThresholdFixed, imageproc.ThresholdOtsu, imageproc.ThresholdAdaptive} {
+		if grid := imageproc.DetectGrid(img, mode); matches(grid.HorizontalLines, grid.VerticalLines) {
+			return grid.HorizontalLines, grid.VerticalLines, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("未能在截图中检测到与标注一致的 %dx%d 网格", rows, cols)
+}
+
+// EvaluateDataset 用指定识别器重新识别数据集（见 internal/dataset）中的样本，与当前标注比较。
+// 清单记录了来源截图与坐标时在来源截图上按实时识别的方式识别，否则直接识别单元格图像
+func EvaluateDataset(recognizer identify.Recognizer, dir string) (Report, error) {
+	samples, err := dataset.Load(dir)
+	if err != nil {
+		return Report{}, err
+	}
+	if len(samples) == 0 {
+		return Report{}, fmt.Errorf("数据集 %s 中没有样本", dir)
+	}
+
+	report := Report{Name: filepath.Base(dir), ByState: make(map[cell.CellState]*Accuracy)}
+	sources := make(map[string]image.Image)
+	for _, s := range samples {
+		var got cell.CellState
+		if s.Source != "" && s.Width > 0 && s.Hight > 0 {
+			img, ok := sources[s.Source]
+			if !ok {
+				if img, err = dataset.LoadPNG(filepath.Join(dir, filepath.FromSlash(s.Source))); err != nil {
+					return Report{}, err
+				}
+				sources[s.Source] = img
+			}
+			got = recognizer.Recognize(img, s.X, s.Y, s.Width, s.Hight)
+		} else {
+			img, err := dataset.LoadPNG(s.Path)
+			if err != nil {
+				return Report{}, err
+			}
+			b := img.Bounds()
+			got = recognizer.Recognize(img, b.Dx()/2, b.Dy()/2, b.Dx(), b.Dy())
+		}
+		report.add(s.Label, got)
+	}
+	return report, nil
+}
+
+func (r *Report) add(want, got cell.CellState) {
+	acc, ok := r.ByState[want]
+	if !ok {
+		acc = &Accuracy{}
+		r.ByState[want] = acc
+	}
+	acc.Total++
+	r.Overall.Total++
+	if got == want {
+		acc.Correct++
+		r.Overall.Correct++
+	}
+}
+
+// TemplateSheet 返回每种状态各一格的单行盘面，用于渲染并提取模板
+func TemplateSheet() [][]cell.GridCell {
+	states := []cell.CellState{cell.QuestionMark, cell.Exploded, cell.Misflag, cell.Mine, cell.Flagged, cell.Unknown, cell.Empty}
+	for n := cell.Number1; n <= cell.Number8; n++ {
+		states = append(states, n)
+	}
+	row := make([]cell.GridCell, len(states))
+	for j, s := range states {
+		row[j] = cell.GridCell{State: s, Position: image.Point{X: j}}
+	}
+	return [][]cell.GridCell{row}
+}
+
+// RenderedTemplates 从渲染器绘制的 TemplateSheet 提取模板。
+// 模板与 SampleBoard 渲染出的盘面同源，只应用于评估真实截图或数据集
+func RenderedTemplates(cellSize int) *identify.TemplateClassifier {
+	sheet := TemplateSheet()
+	horizontalLines, verticalLines := render.Lines(len(sheet), len(sheet[0]), cellSize)
+	return identify.ExtractTemplates(render.Board(sheet, cellSize), horizontalLines, verticalLines, sheet)
+}
+
+// WriteTable 以表格形式输出各退化下每种状态的准确率
+func WriteTable(w io.Writer, reports []Report) {
+	stateSet := make(map[cell.CellState]struct{})
+	for _, r := range reports {
+		for s := range r.ByState {
+			stateSet[s] = struct{}{}
+		}
+	}
+	states := make([]cell.CellState, 0, len(stateSet))
+	for s := range stateSet {
+		states = append(states, s)
+	}
+	sort.Slice(states, func(a, b int) bool { return states[a] < states[b] })
+
+	fmt.Fprintf(w, "%-14s", "退化")
+	for _, s := range states {
+		fmt.Fprintf(w, "%8s", identify.StateName(s))
+	}
+	fmt.Fprintf(w, "%8s\n", "总体")
+	for _, r := range reports {
+		fmt.Fprintf(w, "%-14s", r.Name)
+		for _, s := range states {
+			if acc, ok := r.ByState[s]; ok {
+				fmt.Fprintf(w, "%7.1f%%", acc.Rate()*100)
+			} else {
+				fmt.Fprintf(w, "%8s", "-")
+			}
+		}
+		fmt.Fprintf(w, "%7.1f%%\n", r.Overall.Rate()*100)
+	}
+}
+
+func countMines(mines [][]bool, row, col int) int {
+	count := 0
+	for i := row - 1; i <= row+1; i++ {
+		for j := col - 1; j <= col+1; j++ {
+			if i >= 0 && i < len(mines) && j >= 0 && j < len(mines[i]) && mines[i][j] {
+				count++
+			}
+		}
+	}
+	return count
+}
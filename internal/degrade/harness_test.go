@@ -0,0 +1,72 @@
+package degrade_test
+
+import (
+	"image"
+	"testing"
+
+	"minego/internal/cell"
+	"minego/internal/dataset"
+	"minego/internal/degrade"
+	"minego/internal/header"
+	"minego/internal/identify"
+	"minego/internal/imgpos"
+	"minego/internal/render"
+)
+
+func TestSampleBoardSeedsRareStates(t *testing.T) {
+	for seed := uint64(1); seed <= 5; seed++ {
+		truth := degrade.SampleBoard(16, 30, 99, seed)
+		seen := make(map[cell.CellState]bool)
+		for _, row := range truth {
+			for _, c := range row {
+				seen[c.State] = true
+			}
+		}
+		for _, s := range []cell.CellState{cell.QuestionMark, cell.Exploded, cell.Misflag, cell.Unobserved, cell.Number6, cell.Number7, cell.Number8} {
+			if !seen[s] {
+				t.Errorf("种子 %d 的盘面中没有 %s", seed, identify.StateName(s))
+			}
+		}
+	}
+}
+
+func TestEvaluateCaptureAndDataset(t *testing.T) {
+	truth := degrade.SampleBoard(16, 30, 99, 1)
+	want := degrade.EvaluateWith(identify.ColorRecognizer{}, truth, render.DefaultCellSize, []degrade.Degradation{degrade.Identity()})[0]
+	board, _ := render.Window(truth, render.DefaultCellSize, 99, 0, header.FacePlaying)
+
+	// 截图含信息栏与边框，网格线须从截图中检测
+	got, err := degrade.EvaluateCapture(identify.ColorRecognizer{}, "window", board, truth)
+	if err != nil {
+		t.Fatalf("评估截图失败: %v", err)
+	}
+	if got.Overall != want.Overall {
+		t.Errorf("截图准确率 %d/%d, 渲染盘面 %d/%d", got.Overall.Correct, got.Overall.Total, want.Overall.Correct, want.Overall.Total)
+	}
+
+	// 以真值作为数据集的标注导出
+	dir := t.TempDir()
+	writer, err := dataset.Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain := render.Board(truth, render.DefaultCellSize)
+	horizontalLines, verticalLines := render.Lines(len(truth), len(truth[0]), render.DefaultCellSize)
+	cells := identify.IdentifyWith(identify.ColorRecognizer{}, imgpos.NewImageWithOffset(plain, image.Point{}), horizontalLines, verticalLines)
+	for i, row := range cells {
+		for j := range row {
+			cells[i][j].State = truth[i][j].State
+		}
+	}
+	if _, err := writer.Export(plain, cells); err != nil {
+		t.Fatal(err)
+	}
+	writer.Close()
+	got, err = degrade.EvaluateDataset(identify.ColorRecognizer{}, dir)
+	if err != nil {
+		t.Fatalf("评估数据集失败: %v", err)
+	}
+	if got.Overall != want.Overall {
+		t.Errorf("数据集准确率 %d/%d, 渲染盘面 %d/%d", got.Overall.Correct, got.Overall.Total, want.Overall.Correct, want.Overall.Total)
+	}
+}
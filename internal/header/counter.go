@@ -0,0 +1,359 @@
+// Package header 解析雷区上方的信息栏：七段数码管的剩余雷数与计时器
+package header
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"sort"
+)
+
+// 七段数码管的段位编号：a 上, b 右上, c 右下, d 下, e 左下, f 左上, g 中
+const (
+	segA = 1 << iota
+	segB
+	segC
+	segD
+	segE
+	segF
+	segG
+)
+
+const minusSegments = segG
+
+var digitSegments = [10]uint8{
+	segA | segB | segC | segD | segE | segF,        // 0
+	segB | segC,                                    // 1
+	segA | segB | segD | segE | segG,               // 2
+	segA | segB | segC | segD | segG,               // 3
+	segB | segC | segF | segG,                      // 4
+	segA | segC | segD | segF | segG,               // 5
+	segA | segC | segD | segE | segF | segG,        // 6
+	segA | segB | segC,                             // 7
+	segA | segB | segC | segD | segE | segF | segG, // 8
+	segA | segB | segC | segD | segF | segG,        // 9
+}
+
+const counterDigits = 3 // 计数器固定显示 3 位
+
+const (
+	litMinRed      = 140 // 点亮段位的最低红色分量
+	minLitContrast = 60  // 面板内亮暗段位的最小差距，低于该值时按 litMinRed 判断
+)
+
+// SegmentRects 返回宽 w、高 h 的数字框内七段的位置（相对数字框左上角），顺序为 a 到 g
+func SegmentRects(w, h int) [7]image.Rectangle {
+	t := max(w/4, 1)
+	return [7]image.Rectangle{
+		image.Rect(t, 0, w-t, t),
+		image.Rect(w-t, t, w, h/2),
+		image.Rect(w-t, h/2, w, h-t),
+		image.Rect(t, h-t, w-t, h),
+		image.Rect(0, h/2, t, h-t),
+		image.Rect(0, t, t, h/2),
+		image.Rect(t, h/2-t/2, w-t, h/2-t/2+t),
+	}
+}
+
+// DigitRects 返回数码管面板内各位数字框的位置
+func DigitRects(panel image.Rectangle) [counterDigits]image.Rectangle {
+	var rects [counterDigits]image.Rectangle
+	inner := panel.Inset(1)
+	colW := inner.Dx() / counterDigits
+	pad := max(colW/8, 1)
+	for k := range rects {
+		col := image.Rect(inner.Min.X+k*colW, inner.Min.Y, inner.Min.X+(k+1)*colW, inner.Max.Y)
+		rects[k] = col.Inset(pad)
+	}
+	return rects
+}
+
+// Encode 将数值编码为各位的段位掩码，负数首位显示减号，超出范围时截断到可显示的值
+func Encode(value int) [counterDigits]uint8 {
+	var masks [counterDigits]uint8
+	negative := value < 0
+	if negative {
+		value = min(-value, 99)
+	} else {
+		value = min(value, 999)
+	}
+	for k := counterDigits - 1; k >= 0; k-- {
+		masks[k] = digitSegments[value%10]
+		value /= 10
+	}
+	if negative {
+		masks[0] = minusSegments
+	}
+	return masks
+}
+
+// Decode 将各位段位掩码解码为数值，全灭的位视为 0（部分客户端不显示前导零）
+func Decode(masks [counterDigits]uint8) (int, error) {
+	value := 0
+	negative := false
+	for k, mask := range masks {
+		if k == 0 && mask == minusSegments {
+			negative = true
+			continue
+		}
+		digit := -1
+		if mask == 0 {
+			digit = 0
+		}
+		for d, segments := range digitSegments {
+			if segments == mask {
+				digit = d
+			}
+		}
+		if digit < 0 {
+			return 0, fmt.Errorf("第 %d 位无法识别的段位组合 %07b", k+1, mask)
+		}
+		value = value*10 + digit
+	}
+	if negative {
+		value = -value
+	}
+	return value, nil
+}
+
+// Counters 信息栏中的两个计数器
+type Counters struct {
+	Mines     int // 剩余雷数（总雷数减去旗帜数），插旗过多时为负
+	Seconds   int // 已用时间
+	MinesRect image.Rectangle
+	TimeRect  image.Rectangle
+}
+
+// Region 返回窗口中雷区上方的信息栏范围：横向与窗口同宽，纵向从窗口顶部到雷区上沿
+func Region(window, board image.Rectangle) image.Rectangle {
+	return image.Rect(window.Min.X, window.Min.Y, window.Max.X, board.Min.Y).Intersect(window)
+}
+
+// ReadCounters 在信息栏截图中定位两个数码管面板并读取数值，左侧为剩余雷数，右侧为计时器
+func ReadCounters(img image.Image) (Counters, error) {
+	panels := FindPanels(img)
+	if len(panels) < 2 {
+		return Counters{}, fmt.Errorf("只找到 %d 个数码管面板", len(panels))
+	}
+	minesRect, timeRect := panels[0], panels[len(panels)-1]
+	mines, err := ReadPanel(img, minesRect)
+	if err != nil {
+		return Counters{}, fmt.Errorf("读取剩余雷数失败: %v", err)
+	}
+	seconds, err := ReadPanel(img, timeRect)
+	if err != nil {
+		return Counters{}, fmt.Errorf("读取计时器失败: %v", err)
+	}
+	return Counters{Mines: mines, Seconds: seconds, MinesRect: minesRect, TimeRect: timeRect}, nil
+}
+
+// ReadPanel 读取单个数码管面板的数值。
+// 数字框取面板内红色像素（含未点亮的暗红段位）的范围，不依赖面板边缘在模糊后的位置；
+// 各段的亮度取段位中部的平均红色分量，以面板内最亮与最暗段位的中点为阈值。
+// 模糊与缩放会让数字框偏差一两个像素，读数不合法时在附近微调数字框，取亮暗区分最明显的合法读数
+func ReadPanel(img image.Image, panel image.Rectangle) (int, error) {
+	area := segmentArea(img, panel)
+	if area.Empty() {
+		return 0, fmt.Errorf("面板 %v 中没有段位", panel)
+	}
+	digits := splitDigits(img, area)
+
+	lo, hi := 255.0, 0.0
+	for _, digit := range digits {
+		for _, level := range segmentLevels(img, digit) {
+			lo, hi = min(lo, level), max(hi, level)
+		}
+	}
+	threshold := float64(litMinRed)
+	if hi-lo >= minLitContrast {
+		threshold = (lo + hi) / 2
+	}
+
+	var masks [counterDigits]uint8
+	for k, digit := range digits {
+		masks[k], _ = readDigit(img, digit, threshold)
+		if validMask(masks[k], k == 0) {
+			continue
+		}
+		bestMargin := -1.0
+		for _, d := range nudges(digit) {
+			mask, margin := readDigit(img, d, threshold)
+			if validMask(mask, k == 0) && margin > bestMargin {
+				masks[k], bestMargin = mask, margin
+			}
+		}
+	}
+	return Decode(masks)
+}
+
+// readDigit 返回数字框的段位掩码，以及各段亮度与阈值的最小差距
+func readDigit(img image.Image, digit image.Rectangle, threshold float64) (uint8, float64) {
+	var mask uint8
+	margin := 255.0
+	for s, level := range segmentLevels(img, digit) {
+		if level >= threshold {
+			mask |= 1 << s
+		}
+		margin = min(margin, math.Abs(level-threshold))
+	}
+	return mask, margin
+}
+
+// segmentLevels 返回数字框内七段各自中部的平均红色分量
+func segmentLevels(img image.Image, digit image.Rectangle) [7]float64 {
+	var levels [7]float64
+	for s, seg := range SegmentRects(digit.Dx(), digit.Dy()) {
+		// 沿长边两端各去掉半个段宽，避免相邻段位的模糊溢出
+		if seg.Dx() > seg.Dy() {
+			seg.Min.X, seg.Max.X = seg.Min.X+seg.Dy()/2, seg.Max.X-seg.Dy()/2
+		} else {
+			seg.Min.Y, seg.Max.Y = seg.Min.Y+seg.Dx()/2, seg.Max.Y-seg.Dx()/2
+		}
+		levels[s] = meanRed(img, seg.Add(digit.Min))
+	}
+	return levels
+}
+
+// nudges 返回数字框四条边各自移动 -1、0、1 像素得到的所有候选框
+func nudges(digit image.Rectangle) []image.Rectangle {
+	rects := make([]image.Rectangle, 0, 81)
+	for _, left := range [3]int{-1, 0, 1} {
+		for _, top := range [3]int{-1, 0, 1} {
+			for _, right := range [3]int{-1, 0, 1} {
+				for _, bottom := range [3]int{-1, 0, 1} {
+					rects = append(rects, image.Rect(digit.Min.X+left, digit.Min.Y+top, digit.Max.X+right, digit.Max.Y+bottom))
+				}
+			}
+		}
+	}
+	return rects
+}
+
+// validMask 掩码是否为可显示的内容：数字、全灭或首位的减号
+func validMask(mask uint8, first bool) bool {
+	if mask == 0 || (first && mask == minusSegments) {
+		return true
+	}
+	for _, segments := range digitSegments {
+		if segments == mask {
+			return true
+		}
+	}
+	return false
+}
+
+// segmentArea 返回区域内红色像素（亮或暗的段位）的外接矩形
+func segmentArea(img image.Image, rect image.Rectangle) image.Rectangle {
+	area := image.Rectangle{}
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			r, g, b, _ := img.At(x, y).RGBA()
+			r, g, b = r>>8, g>>8, b>>8
+			if r >= 60 && r > g+40 && r > b+40 {
+				area = area.Union(image.Rect(x, y, x+1, y+1))
+			}
+		}
+	}
+	return area
+}
+
+// splitDigits 将段位范围切分为等宽、等间距的各位数字框。
+// 数字宽度取三等分后各列段位范围的最小值（相邻数字的红色溢出只会让范围变宽），两端数字与段位范围的外沿对齐
+func splitDigits(img image.Image, area image.Rectangle) [counterDigits]image.Rectangle {
+	width := area.Dx()
+	for k := range counterDigits {
+		col := image.Rect(area.Min.X+k*area.Dx()/counterDigits, area.Min.Y, area.Min.X+(k+1)*area.Dx()/counterDigits, area.Max.Y)
+		if w := segmentArea(img, col).Dx(); w > 0 {
+			width = min(width, w)
+		}
+	}
+	pitch := float64(area.Dx()-width) / (counterDigits - 1)
+
+	var rects [counterDigits]image.Rectangle
+	for k := range rects {
+		x := area.Min.X + int(float64(k)*pitch+0.5)
+		rects[k] = image.Rect(x, area.Min.Y, x+width, area.Max.Y)
+	}
+	return rects
+}
+
+// FindPanels 返回截图中所有数码管面板（黑底并含红色段位的矩形区域），按从左到右排序
+func FindPanels(img image.Image) []image.Rectangle {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	mask := make([]bool, w*h)
+	for y := range h {
+		for x := range w {
+			mask[y*w+x] = isPanelPixel(img, b.Min.X+x, b.Min.Y+y)
+		}
+	}
+
+	panels := make([]image.Rectangle, 0)
+	seen := make([]bool, w*h)
+	for start := range mask {
+		if !mask[start] || seen[start] {
+			continue
+		}
+		// 广度优先求连通域的外接矩形
+		rect := image.Rect(start%w, start/w, start%w+1, start/w+1)
+		queue := []int{start}
+		seen[start] = true
+		count, lit := 0, 0
+		for len(queue) > 0 {
+			k := queue[0]
+			queue = queue[1:]
+			x, y := k%w, k/w
+			count++
+			if isLit(img, b.Min.X+x, b.Min.Y+y) {
+				lit++
+			}
+			rect = rect.Union(image.Rect(x, y, x+1, y+1))
+			for _, d := range [4][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
+				nx, ny := x+d[0], y+d[1]
+				if nx < 0 || ny < 0 || nx >= w || ny >= h {
+					continue
+				}
+				if n := ny*w + nx; mask[n] && !seen[n] {
+					seen[n] = true
+					queue = append(queue, n)
+				}
+			}
+		}
+
+		ratio := float64(rect.Dx()) / float64(rect.Dy())
+		filled := float64(count) / float64(rect.Dx()*rect.Dy())
+		if rect.Dx() >= 15 && rect.Dy() >= 10 && ratio >= 1.2 && ratio <= 3.5 && filled > 0.8 && lit > 0 {
+			panels = append(panels, rect.Add(b.Min))
+		}
+	}
+	sort.Slice(panels, func(a, c int) bool { return panels[a].Min.X < panels[c].Min.X })
+	return panels
+}
+
+// isPanelPixel 黑色底色或红色段位（亮或暗）
+func isPanelPixel(img image.Image, x, y int) bool {
+	r, g, b, _ := img.At(x, y).RGBA()
+	r, g, b = r>>8, g>>8, b>>8
+	return (r < 60 && g < 60 && b < 60) || (r > g+60 && r > b+60)
+}
+
+func isLit(img image.Image, x, y int) bool {
+	r, g, b, _ := img.At(x, y).RGBA()
+	return r>>8 >= litMinRed && g>>8 < 100 && b>>8 < 100
+}
+
+// meanRed 返回区域内像素红色分量的平均值
+func meanRed(img image.Image, rect image.Rectangle) float64 {
+	sum, total := 0, 0
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			r, _, _, _ := img.At(x, y).RGBA()
+			sum += int(r >> 8)
+			total++
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(sum) / float64(total)
+}
@@ -0,0 +1,55 @@
+package header_test
+
+import (
+	"image"
+	"testing"
+
+	"minego/internal/cell"
+	"minego/internal/header"
+	"minego/internal/render"
+)
+
+// headerImage 渲染 9x9 的未翻开盘面，返回雷区上方的信息栏截图
+func headerImage(cellSize, mines, seconds int, face header.FaceState) image.Image {
+	grid := make([][]cell.GridCell, 9)
+	for i := range grid {
+		grid[i] = make([]cell.GridCell, 9)
+		for j := range grid[i] {
+			grid[i][j].State = cell.Unknown
+		}
+	}
+	img, board := render.Window(grid, cellSize, mines, seconds, face)
+	return img.SubImage(header.Region(img.Bounds(), board))
+}
+
+func TestReadCountersAllValues(t *testing.T) {
+	for mines := -5; mines <= 999; mines++ {
+		seconds := 999 - max(mines, 0)
+		got, err := header.ReadCounters(headerImage(render.DefaultCellSize, mines, seconds, header.FacePlaying))
+		if err != nil {
+			t.Fatalf("剩余雷数 %d: 读取计数器失败: %v", mines, err)
+		}
+		if got.Mines != mines || got.Seconds != seconds {
+			t.Errorf("读数 %d/%d, want %d/%d", got.Mines, got.Seconds, mines, seconds)
+		}
+	}
+}
+
+func TestReadCountersCellSizes(t *testing.T) {
+	values := [][2]int{{-5, 0}, {-1, 7}, {0, 999}, {10, 42}, {99, 100}, {999, 1}}
+	for cellSize := 16; cellSize <= 36; cellSize++ {
+		for _, v := range values {
+			got, err := header.ReadCounters(headerImage(cellSize, v[0], v[1], header.FacePlaying))
+			if err != nil {
+				t.Errorf("格子边长 %d, 数值 %v: 读取计数器失败: %v", cellSize, v, err)
+				continue
+			}
+			if got.Mines != v[0] || got.Seconds != v[1] {
+				t.Errorf("格子边长 %d: 读数 %d/%d, want %d/%d", cellSize, got.Mines, got.Seconds, v[0], v[1])
+			}
+			if got.MinesRect.Max.X > got.TimeRect.Min.X {
+				t.Errorf("格子边长 %d: 剩余雷数面板 %v 不在计时器 %v 左侧", cellSize, got.MinesRect, got.TimeRect)
+			}
+		}
+	}
+}
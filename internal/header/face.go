@@ -0,0 +1,130 @@
+package header
+
+import (
+	"fmt"
+	"image"
+)
+
+// FaceState 笑脸按钮显示的状态
+type FaceState int
+
+const (
+	FaceUnknown  FaceState = iota
+	FacePlaying            // 微笑：进行中
+	FacePressing           // 张嘴：鼠标按下未松开
+	FaceWon                // 墨镜：胜利
+	FaceLost               // 叉眼、撇嘴：失败
+)
+
+func (s FaceState) String() string {
+	switch s {
+	case FacePlaying:
+		return "进行中"
+	case FacePressing:
+		return "按下"
+	case FaceWon:
+		return "胜利"
+	case FaceLost:
+		return "失败"
+	default:
+		return "未知"
+	}
+}
+
+// Face 信息栏中的笑脸按钮
+type Face struct {
+	State FaceState
+	Rect  image.Rectangle // 黄色脸部的外接矩形，坐标系与输入截图相同
+}
+
+// Center 返回按钮中心，点击该位置即开始新游戏
+func (f Face) Center() image.Point {
+	return image.Pt((f.Rect.Min.X+f.Rect.Max.X)/2, (f.Rect.Min.Y+f.Rect.Max.Y)/2)
+}
+
+// FindFace 在信息栏截图中定位笑脸（唯一的大块黄色区域）并识别其状态
+func FindFace(img image.Image) (Face, error) {
+	b := img.Bounds()
+	rect := image.Rectangle{}
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if isFacePixel(img, x, y) {
+				rect = rect.Union(image.Rect(x, y, x+1, y+1))
+			}
+		}
+	}
+	if rect.Dx() < 8 || rect.Dy() < 8 {
+		return Face{}, fmt.Errorf("未找到笑脸按钮")
+	}
+	if ratio := float64(rect.Dx()) / float64(rect.Dy()); ratio < 0.7 || ratio > 1.4 {
+		return Face{}, fmt.Errorf("黄色区域 %v 不是笑脸按钮", rect)
+	}
+	return Face{State: classifyFace(img, rect), Rect: rect}, nil
+}
+
+// classifyFace 根据脸部的深色五官判断状态：
+// 上半部横贯的深色带为墨镜；下半部嘴巴窄而圆为张嘴，否则按嘴角与嘴中的高低区分微笑与撇嘴
+func classifyFace(img image.Image, rect image.Rectangle) FaceState {
+	cx := float64(rect.Min.X+rect.Max.X-1) / 2
+	cy := float64(rect.Min.Y+rect.Max.Y-1) / 2
+	r := float64(rect.Dx()) / 2
+
+	widestEyeRow := 0
+	mouth := image.Rectangle{}
+	var centerSum, centerCount, sideSum, sideCount float64
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		v := (float64(y) - cy) / r
+		row := 0
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			u := (float64(x) - cx) / r
+			// 只看脸部内侧，排除轮廓线
+			if u*u+v*v > 0.8*0.8 || !isFeaturePixel(img, x, y) {
+				continue
+			}
+			switch {
+			case v < -0.05:
+				row++
+			case v > 0.1:
+				mouth = mouth.Union(image.Rect(x, y, x+1, y+1))
+				if u > -0.2 && u < 0.2 {
+					centerSum += v
+					centerCount++
+				} else if u < -0.3 || u > 0.3 {
+					sideSum += v
+					sideCount++
+				}
+			}
+		}
+		widestEyeRow = max(widestEyeRow, row)
+	}
+
+	if mouth.Empty() {
+		return FaceUnknown
+	}
+	if float64(mouth.Dx()) <= 0.9*r && float64(mouth.Dx()) < 1.6*float64(mouth.Dy()) {
+		return FacePressing
+	}
+	if centerCount == 0 || sideCount == 0 {
+		return FaceUnknown
+	}
+	smiling := centerSum/centerCount > sideSum/sideCount // 图像坐标向下为正，嘴中低于嘴角即微笑
+	switch {
+	case !smiling:
+		return FaceLost
+	case float64(widestEyeRow) >= 0.9*r:
+		return FaceWon
+	default:
+		return FacePlaying
+	}
+}
+
+func isFacePixel(img image.Image, x, y int) bool {
+	r, g, b, _ := img.At(x, y).RGBA()
+	return r>>8 >= 200 && g>>8 >= 200 && b>>8 <= 100
+}
+
+// isFeaturePixel 明显暗于黄色脸部的像素；阈值较宽，使模糊后只有一像素宽的五官仍可见
+func isFeaturePixel(img image.Image, x, y int) bool {
+	r, g, _, _ := img.At(x, y).RGBA()
+	return r>>8 < 190 && g>>8 < 190
+}
@@ -0,0 +1,36 @@
+package header_test
+
+import (
+	"testing"
+
+	"minego/internal/header"
+)
+
+func TestFindFaceCellSizes(t *testing.T) {
+	states := []header.FaceState{header.FacePlaying, header.FacePressing, header.FaceWon, header.FaceLost}
+	for cellSize := 16; cellSize <= 36; cellSize++ {
+		for _, state := range states {
+			img := headerImage(cellSize, 10, 0, state)
+			face, err := header.FindFace(img)
+			if err != nil {
+				t.Errorf("格子边长 %d, %s: 定位笑脸失败: %v", cellSize, state, err)
+				continue
+			}
+			if face.State != state {
+				t.Errorf("格子边长 %d: 识别为 %s, want %s", cellSize, face.State, state)
+			}
+			// 点击中心应落在信息栏正中的按钮上
+			b := img.Bounds()
+			if c := face.Center(); abs(c.X-(b.Min.X+b.Max.X)/2) > 1 || !c.In(b) {
+				t.Errorf("格子边长 %d, %s: 按钮中心 %v 不在信息栏 %v 正中", cellSize, state, c, b)
+			}
+		}
+	}
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
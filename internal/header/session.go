@@ -0,0 +1,31 @@
+package header
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Session 一局游戏的记录
+type Session struct {
+	Client    string    `json:"client"`
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+	Seconds   int       `json:"seconds"`   // 计时器显示的游戏内用时
+	MineCount int       `json:"mineCount"` // 由剩余雷数与旗帜数推算的地雷总数
+	Result    string    `json:"result"`
+}
+
+// AppendSession 以 JSON Lines 格式追加一条对局记录
+func AppendSession(path string, s Session) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("打开对局记录失败: %v", err)
+	}
+	defer file.Close()
+	if err := json.NewEncoder(file).Encode(s); err != nil {
+		return fmt.Errorf("写入对局记录失败: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package identify
+
+import (
+	"image"
+	"time"
+
+	"minego/internal/cell"
+)
+
+// MaxConflictFrames 同一个不可能的变化连续出现这么多轮后视为真实变化（例如用户手动操作）而接受
+var MaxConflictFrames = 3
+
+// Conflict 一次不可能的状态变化及其处理结果
+type Conflict struct {
+	Position   image.Point    // X 为列，Y 为行
+	From       cell.CellState // 上一轮确认的状态
+	To         cell.CellState // 本轮识别结果
+	Resolved   cell.CellState // 最终采用的状态
+	Recaptured bool           // 重新截图后得到了可能的状态
+	Accepted   bool           // 连续 MaxConflictFrames 轮出现，按真实变化接受
+}
+
+// History 保存上一轮确认的盘面，检查新的识别结果是否存在不可能的状态变化：
+// 翻开的格子不会重新被覆盖，数字不会改变，旗帜只会被右键取消或在失败时显示为插错
+type History struct {
+	Recognizer Recognizer // 重新截图时使用的识别器，为 nil 时使用 DefaultRecognizer
+	previous   [][]cell.GridCell
+	streaks    map[image.Point]streak
+}
+
+// streak 某个格子连续出现同一个不可能变化的轮数
+type streak struct {
+	to     cell.CellState
+	frames int
+}
+
+// NewHistory 创建空的盘面历史，第一次 Check 时直接接受识别结果
+func NewHistory(recognizer Recognizer) *History {
+	return &History{Recognizer: recognizer}
+}
+
+// Possible 从 from 变为 to 是否可能，任一方无法观测时都视为可能
+func Possible(from, to cell.CellState) bool {
+	switch {
+	case from == to, from.Covered(), from == cell.Locked, to == cell.Locked,
+		from == cell.Unobserved, to == cell.Unobserved:
+		return true
+	case from == cell.Flagged:
+		return to.Covered() || to == cell.Misflag
+	default:
+		// 数字、空白、地雷与插错的旗帜一经出现就不会再变化
+		return false
+	}
+}
+
+// Check 对照上一轮确认的盘面检查 cells，原地修正不可能的状态变化并记为新的确认盘面。
+// 每个冲突的格子先用 capture 重新截图识别（最多 attempts 次），得到可能的状态即采用；
+// 否则保留上一轮的状态，除非同一变化已连续出现 MaxConflictFrames 轮。
+// 本轮无法观测而上一轮已翻开的格子沿用上一轮的状态，因为翻开的格子不会再变化。
+// 能观测到的格子全部未翻开而上一轮有已翻开的格子时视为开始了新的一局，直接接受
+func (h *History) Check(cells [][]cell.GridCell, capture func() (image.Image, error), attempts int) ([]Conflict, error) {
+	if h.previous == nil || !sameShape(h.previous, cells) || restarted(h.previous, cells) {
+		h.Reset()
+		h.remember(cells)
+		return nil, nil
+	}
+
+	conflicts := make([]Conflict, 0)
+	pending := make([]int, 0)
+	for i := range cells {
+		for j := range cells[i] {
+			from, to := h.previous[i][j].State, cells[i][j].State
+			if to == cell.Unobserved && !from.Covered() && from != cell.Unobserved {
+				cells[i][j].State, cells[i][j].Confidence = from, h.previous[i][j].Confidence
+				continue
+			}
+			if Possible(from, to) {
+				continue
+			}
+			conflicts = append(conflicts, Conflict{Position: image.Point{X: j, Y: i}, From: from, To: to, Resolved: from})
+			pending = append(pending, len(conflicts)-1)
+		}
+	}
+
+	recognizer := h.Recognizer
+	if recognizer == nil {
+		recognizer = DefaultRecognizer
+	}
+	var err error
+	for attempt := 0; attempt < attempts && len(pending) > 0; attempt++ {
+		time.Sleep(RecaptureDelay)
+		var img image.Image
+		if img, err = capture(); err != nil {
+			break
+		}
+		remaining := pending[:0]
+		for _, k := range pending {
+			c := &conflicts[k]
+			gc := &cells[c.Position.Y][c.Position.X]
+			state, confidence := recognizeWithConfidence(recognizer, img, gc.X, gc.Y, gc.Width, gc.Hight)
+			if Possible(c.From, state) {
+				c.Resolved, c.Recaptured = state, true
+				gc.State, gc.Confidence = state, confidence
+				continue
+			}
+			remaining = append(remaining, k)
+		}
+		pending = remaining
+	}
+
+	streaks := make(map[image.Point]streak)
+	for _, k := range pending {
+		c := &conflicts[k]
+		s := h.streaks[c.Position]
+		if s.to != c.To {
+			s = streak{to: c.To}
+		}
+		s.frames++
+		gc := &cells[c.Position.Y][c.Position.X]
+		if s.frames >= MaxConflictFrames {
+			c.Resolved, c.Accepted = c.To, true
+			continue
+		}
+		streaks[c.Position] = s
+		gc.State, gc.Confidence = c.From, h.previous[c.Position.Y][c.Position.X].Confidence
+	}
+	h.streaks = streaks
+	h.remember(cells)
+	return conflicts, err
+}
+
+// Reset 丢弃历史，下一次 Check 直接接受识别结果（例如重新开局后）
+func (h *History) Reset() {
+	h.previous, h.streaks = nil, nil
+}
+
+// remember 保存盘面的副本作为上一轮确认的结果，无法观测的格子保留更早的状态
+func (h *History) remember(cells [][]cell.GridCell) {
+	previous := make([][]cell.GridCell, len(cells))
+	for i := range cells {
+		previous[i] = make([]cell.GridCell, len(cells[i]))
+		copy(previous[i], cells[i])
+		if h.previous == nil {
+			continue
+		}
+		for j := range previous[i] {
+			if previous[i][j].State == cell.Unobserved {
+				previous[i][j] = h.previous[i][j]
+			}
+		}
+	}
+	h.previous = previous
+}
+
+func sameShape(a, b [][]cell.GridCell) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if len(a[i]) != len(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// restarted 能观测到的格子全部未翻开，且其中至少有一个上一轮已翻开，说明开始了新的一局
+func restarted(previous, cells [][]cell.GridCell) bool {
+	reverted := 0
+	for i := range cells {
+		for j, c := range cells[i] {
+			if c.State == cell.Unobserved {
+				continue
+			}
+			if !c.State.Covered() {
+				return false
+			}
+			if from := previous[i][j].State; !from.Covered() && from != cell.Unobserved {
+				reverted++
+			}
+		}
+	}
+	return reverted > 0
+}
@@ -10,15 +10,30 @@ import (
 	"minego/pkg/colorutil"
 
 	"os"
+	"runtime"
 	"strconv"
+	"strings"
+	"sync"
 )
 
 type identifier struct {
 	imgpos *imgpos.ImageWithOffset
 }
 
+// IdentifyMinesweeper 识别雷区并将结果保存到 GridcellRec.txt
 func IdentifyMinesweeper(imgpos *imgpos.ImageWithOffset, horizontalLines, verticalLines []int) [][]cell.GridCell {
+	result := Identify(imgpos, horizontalLines, verticalLines)
+	SaveResultToFile(result, "GridcellRec.txt")
+	return result
+}
+
+// Identify 使用 DefaultRecognizer 按网格线识别每个单元格的状态
+func Identify(imgpos *imgpos.ImageWithOffset, horizontalLines, verticalLines []int) [][]cell.GridCell {
+	return IdentifyWith(DefaultRecognizer, imgpos, horizontalLines, verticalLines)
+}
 
+// IdentifyWith 使用指定识别器按网格线识别每个单元格的状态，各行由多个 goroutine 并发识别
+func IdentifyWith(recognizer Recognizer, imgpos *imgpos.ImageWithOffset, horizontalLines, verticalLines []int) [][]cell.GridCell {
 	rows := len(horizontalLines) - 1
 	cols := len(verticalLines) - 1
 
@@ -26,33 +41,61 @@ func IdentifyMinesweeper(imgpos *imgpos.ImageWithOffset, horizontalLines, vertic
 	result := make([][]cell.GridCell, rows)
 	for i := range result {
 		result[i] = make([]cell.GridCell, cols) // 初始化每行的列切片
-		for j := range result[i] {
-			y := (horizontalLines[i] + horizontalLines[i+1]) / 2
-			x := (verticalLines[j] + verticalLines[j+1]) / 2
-			width := (verticalLines[j+1] - verticalLines[j])
-			hight := (horizontalLines[i+1] - horizontalLines[i])
-
-			state := recognizeColor(imgpos.Image, x, y, width, hight)
-			result[i][j] = cell.GridCell{
-				Offset: imgpos.RelativePosition(),
-				State:  state,
-				X:      x,
-				Y:      y,
-				Width:  width,
-				Hight:  hight,
-				Position: image.Point{
-					X: j,
-					Y: i,
-				},
-				Color: imgpos.Image.At(imgpos.Image.Bounds().Min.X+x, imgpos.Image.Bounds().Min.Y+y),
-			}
-		}
 	}
 
-	SaveResultToFile(result, "GridcellRec.txt")
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(runtime.NumCPU(), rows) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				identifyRow(recognizer, imgpos, horizontalLines, verticalLines, i, result[i])
+			}
+		}()
+	}
+	for i := range rows {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
 	return result
 }
 
+// identifyRow 识别第 i 行的单元格并写入 row
+func identifyRow(recognizer Recognizer, imgpos *imgpos.ImageWithOffset, horizontalLines, verticalLines []int, i int, row []cell.GridCell) {
+	pixels := newPixelReader(imgpos.Image)
+	for j := range row {
+		row[j] = identifyCell(recognizer, imgpos, pixels, horizontalLines, verticalLines, i, j)
+	}
+}
+
+// identifyCell 识别第 i 行第 j 列的单元格
+func identifyCell(recognizer Recognizer, imgpos *imgpos.ImageWithOffset, pixels pixelReader, horizontalLines, verticalLines []int, i, j int) cell.GridCell {
+	bounds := imgpos.Image.Bounds()
+	y := (horizontalLines[i] + horizontalLines[i+1]) / 2
+	x := (verticalLines[j] + verticalLines[j+1]) / 2
+	width := (verticalLines[j+1] - verticalLines[j])
+	hight := (horizontalLines[i+1] - horizontalLines[i])
+
+	state, confidence := recognizeWithConfidence(recognizer, imgpos.Image, x, y, width, hight)
+	r, g, b := pixels.at(bounds.Min.X+x, bounds.Min.Y+y)
+	return cell.GridCell{
+		Offset:     imgpos.RelativePosition(),
+		State:      state,
+		Confidence: confidence,
+		X:          x,
+		Y:          y,
+		Width:      width,
+		Hight:      hight,
+		Position: image.Point{
+			X: j,
+			Y: i,
+		},
+		Color: color.RGBA{r, g, b, 255},
+	}
+}
+
 var (
 	BackgroundColor     = color.RGBA{255, 255, 255, 255}
 	Number1FeatureColor = color.RGBA{65, 79, 188, 255}
@@ -62,29 +105,115 @@ var (
 	Number5Color        = color.RGBA{124, 0, 2, 255}
 	Number6Color        = color.RGBA{12, 119, 116, 255}
 	FlaggedColor        = color.RGBA{247, 247, 244, 255}
+	// 以下四种特征色是合成的占位值，只与 render 的绘制方式一致，没有用真实客户端截图核对过。
+	// 实际使用前应以包含这些状态的带标注截图运行 cmd/calibrate，由主题覆盖
+	MineColor         = color.RGBA{17, 17, 17, 255}   // 翻开的地雷（"多条命"变体中踩雷后仍留在盘面上）
+	ExplodedColor     = color.RGBA{236, 80, 64, 255}  // 踩中地雷的红色背景
+	MisflagColor      = color.RGBA{250, 140, 0, 255}  // 插错旗帜上的叉
+	QuestionMarkColor = color.RGBA{255, 220, 60, 255} // "?" 标记
+	EmptyMinRed       = uint8(170)                    // 中心像素红色分量高于该值时视为已翻开的空白格
+	// CoveredColor 与 CoveredColorRange 同样是只与 render 一致的占位值：单元格中心附近有与 CoveredColor
+	// 距离（8 位 L1）小于 CoveredColorRange 的像素才视为覆盖格，否则判为 cell.Unobserved，求解器不会点击。
+	// 真实客户端的覆盖格有渐变与悬停高亮，应先用 cmd/main -calibrate 或 cmd/calibrate 标定，由主题覆盖
+	CoveredColor      = color.RGBA{60, 110, 200, 255}
+	CoveredColorRange = 90
+	// MineMinCoverage 地雷探测窗口中接近 MineColor 的像素至少占该比例才视为地雷，
+	// 避免把旗帜同为深色的细旗杆与底座识别成地雷
+	MineMinCoverage = 0.5
 )
 
-func recognizeColor(img image.Image, x, y int, width, hight int) cell.CellState {
+// Recognizer 单元格识别器，(x, y) 为单元格中心相对图像左上角的坐标。
+// IdentifyWith 会并发调用同一个识别器，实现不能修改共享状态
+type Recognizer interface {
+	Recognize(img image.Image, x, y, width, hight int) cell.CellState
+}
+
+// ConfidenceRecognizer 能同时给出识别置信度（0 到 1）的识别器
+type ConfidenceRecognizer interface {
+	Recognizer
+	RecognizeWithConfidence(img image.Image, x, y, width, hight int) (cell.CellState, float64)
+}
+
+// recognizeWithConfidence 不支持置信度的识别器视为完全确定
+func recognizeWithConfidence(recognizer Recognizer, img image.Image, x, y, width, hight int) (cell.CellState, float64) {
+	if r, ok := recognizer.(ConfidenceRecognizer); ok {
+		return r.RecognizeWithConfidence(img, x, y, width, hight)
+	}
+	return recognizer.Recognize(img, x, y, width, hight), 1
+}
+
+// DefaultRecognizer Identify 与 IdentifyMinesweeper 使用的识别器
+var DefaultRecognizer Recognizer = ColorRecognizer{}
+
+// ColorRecognizer 基于特征颜色探针的识别器
+type ColorRecognizer struct{}
+
+// Recognize 实现 Recognizer 接口
+func (ColorRecognizer) Recognize(img image.Image, x, y, width, hight int) cell.CellState {
+	return recognizeColor(newPixelReader(img), x, y, width, hight)
+}
+
+// RecognizeWithConfidence 实现 ConfidenceRecognizer 接口。
+// 命中特征色时完全确定；空白格与覆盖格按中心像素红色分量区分，越接近 EmptyMinRed 越不确定；
+// 无法识别（cell.Unobserved）时置信度为 0，由 Refine 重新截图
+func (ColorRecognizer) RecognizeWithConfidence(img image.Image, x, y, width, hight int) (cell.CellState, float64) {
+	pixels := newPixelReader(img)
+	state := recognizeColor(pixels, x, y, width, hight)
+	switch state {
+	case cell.Unobserved:
+		return state, 0
+	case cell.Empty, cell.Unknown:
+	default:
+		return state, 1
+	}
+	r, _, _ := pixels.at(img.Bounds().Min.X+x, img.Bounds().Min.Y+y)
+	return state, min(float64(absDiff(int(r), int(EmptyMinRed)))/emptyRedMargin, 1)
+}
+
+// emptyRedMargin 中心像素红色分量距 EmptyMinRed 达到该值时视为完全确定
+const emptyRedMargin = 40
+
+func absDiff(a, b int) int {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
+
+// recognizeColor 依次在单元格内探测各状态的特征色。所有探测共用同一个 pixelReader，
+// 目标色以 color.RGBA 值传入，识别一个单元格不分配内存
+func recognizeColor(pixels pixelReader, x, y int, width, hight int) cell.CellState {
 	rang := width / 6
-	if hasColor(img, x, y, rang/2, Number1FeatureColor) {
+	if hasColor(pixels, x, y, rang/2, Number1FeatureColor) {
 		return cell.Number1
-	} else if hasColorWithinRange(img, x, y, rang, Number2FeatureColor, 5) {
+	} else if hasColorWithinRange(pixels, x, y, rang, Number2FeatureColor, 5) {
 		return cell.Number2
-	} else if hasColorWithinRange(img, x, y, rang, Number3Color, 3) {
+	} else if hasColorWithinRange(pixels, x, y, rang, Number3Color, 3) {
 		return cell.Number3
-	} else if hasColorWithinRange(img, x, y, rang, Number4Color, 5) {
+	} else if hasColorWithinRange(pixels, x, y, rang, Number4Color, 5) {
 		return cell.Number4
-	} else if hasColorWithinRange(img, x, y, rang, Number5Color, 5) {
+	} else if hasColorWithinRange(pixels, x, y, rang, Number5Color, 5) {
 		return cell.Number5
-	} else if hasColorWithinRange(img, x, y, rang, Number6Color, 5) {
+	} else if hasColorWithinRange(pixels, x, y, rang, Number6Color, 5) {
 		return cell.Number6
-	} else if hasColorWithinRange(img, x, y, 17, FlaggedColor, 25) {
+	} else if hasColorWithinRange(pixels, x-width/3, y-hight/3, 2, ExplodedColor, 20) {
+		return cell.Exploded
+	} else if hasColorWithinRange(pixels, x, y, width/3, MisflagColor, 20) {
+		return cell.Misflag
+	} else if hasColorWithinRange(pixels, x, y, rang, QuestionMarkColor, 20) {
+		return cell.QuestionMark
+	} else if countColorWithinRange(pixels, x, y, rang, MineColor, 30) >= int(MineMinCoverage*float64((2*rang+1)*(2*rang+1))) {
+		return cell.Mine
+	} else if hasColorWithinRange(pixels, x, y, 17, FlaggedColor, 25) {
 		return cell.Flagged
-	} else if r, _, _, _ := img.At(img.Bounds().Min.X+x, img.Bounds().Min.Y+y).RGBA(); r > 170*256 {
+	} else if r, _, _ := pixels.at(pixels.img.Bounds().Min.X+x, pixels.img.Bounds().Min.Y+y); r >= EmptyMinRed {
 		return cell.Empty
+	} else if hasColorWithinRange(pixels, x, y, rang, CoveredColor, CoveredColorRange) {
+		return cell.Unknown
 	}
 
-	return cell.Unknown
+	// 不像任何已知状态，例如被其他窗口或指针遮挡
+	return cell.Unobserved
 }
 
 // SaveResultToFile 将网格状态矩阵保存为文本文件
@@ -110,15 +239,91 @@ func SaveResultToFile(result [][]cell.GridCell, filePath string) error {
 	return nil
 }
 
+// LoadResultFromFile 读取 SaveResultToFile 写出的文本盘面
+func LoadResultFromFile(filePath string) ([][]cell.GridCell, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("读取文件失败: %v", err)
+	}
+	return ParseResult(string(data))
+}
+
+// ParseResult 解析文本盘面，每行一排格子，格子之间以空白分隔
+func ParseResult(text string) ([][]cell.GridCell, error) {
+	result := make([][]cell.GridCell, 0)
+	for lineNo, line := range strings.Split(text, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(result) > 0 && len(fields) != len(result[0]) {
+			return nil, fmt.Errorf("第 %d 行有 %d 列，应为 %d 列", lineNo+1, len(fields), len(result[0]))
+		}
+		row := make([]cell.GridCell, len(fields))
+		for j, field := range fields {
+			state, ok := stringToCellState(field)
+			if !ok {
+				return nil, fmt.Errorf("第 %d 行第 %d 列无法识别的状态 %q", lineNo+1, j+1, field)
+			}
+			row[j] = cell.GridCell{State: state, Position: image.Point{X: j, Y: len(result)}}
+		}
+		result = append(result, row)
+	}
+	return result, nil
+}
+
+// stringToCellState cellStateToString 的逆映射
+func stringToCellState(s string) (cell.CellState, bool) {
+	switch s {
+	case "-":
+		return cell.Unobserved, true
+	case "X":
+		return cell.Exploded, true
+	case "W":
+		return cell.Misflag, true
+	case "Q":
+		return cell.QuestionMark, true
+	case "M":
+		return cell.Mine, true
+	case "F":
+		return cell.Flagged, true
+	case "?":
+		return cell.Unknown, true
+	case "L":
+		return cell.Locked, true
+	case "E":
+		return cell.Empty, true
+	}
+	if n, err := strconv.Atoi(s); err == nil && n >= 1 && n <= 8 {
+		return cell.Number1 + cell.CellState(n-1), true
+	}
+	return 0, false
+}
+
+// StateName 返回状态在文本盘面中的字符表示
+func StateName(state cell.CellState) string {
+	return cellStateToString(state)
+}
+
 // CellState字符串映射
 func cellStateToString(state cell.CellState) string {
 	switch state {
+	case cell.Unobserved:
+		return "-"
+	case cell.Exploded:
+		return "X"
+	case cell.Misflag:
+		return "W"
+	case cell.QuestionMark:
+		return "Q"
 	case cell.Mine:
 		return "M"
 	case cell.Flagged:
 		return "F"
 	case cell.Unknown:
 		return "?"
+	case cell.Locked:
+		return "L"
 	case cell.Empty:
 		return "E"
 	default:
@@ -130,15 +335,19 @@ func cellStateToString(state cell.CellState) string {
 	}
 }
 
-func hasColor(img image.Image, x, y int, rang int, targetColor color.Color) bool {
-	minX := img.Bounds().Min.X
-	minY := img.Bounds().Min.Y
-	tr, tg, tb, _ := targetColor.RGBA()
-	for i := -rang; i <= rang; i++ {
-		for j := -rang; j <= rang; j++ {
-			// 获取当前像素颜色（16-bit值）
-			nr, ng, nb, _ := img.At(minX+x+i, minY+y+j).RGBA()
-			if nr == tr && ng == tg && nb == tb {
+func hasColor(pixels pixelReader, x, y int, rang int, targetColor color.RGBA) bool {
+	return hasColorWithinRange(pixels, x, y, rang, targetColor, 1)
+}
+
+// hasColorWithinRange 以 (x, y)（相对图像左上角）为中心、边长 2*rang+1 的窗口内是否有与目标色 L1 距离（8 位）小于 colorRange 的像素
+func hasColorWithinRange(pixels pixelReader, x, y int, rang int, targetColor color.RGBA, colorRange int) bool {
+	minX := pixels.img.Bounds().Min.X
+	minY := pixels.img.Bounds().Min.Y
+
+	for j := -rang; j <= rang; j++ {
+		for i := -rang; i <= rang; i++ {
+			r, g, b := pixels.at(minX+x+i, minY+y+j)
+			if dist8(r, g, b, targetColor.R, targetColor.G, targetColor.B) < colorRange {
 				return true
 			}
 		}
@@ -146,20 +355,21 @@ func hasColor(img image.Image, x, y int, rang int, targetColor color.Color) bool
 	return false
 }
 
-func hasColorWithinRange(img image.Image, x, y int, rang int, targetColor color.Color, colorRange int) bool {
-	minX := img.Bounds().Min.X
-	minY := img.Bounds().Min.Y
-
-	for i := -rang; i <= rang; i++ {
-		for j := -rang; j <= rang; j++ {
-			// 获取当前像素颜色（16-bit值）
+// countColorWithinRange 与 hasColorWithinRange 相同的窗口内，与目标色 L1 距离（8 位）小于 colorRange 的像素数
+func countColorWithinRange(pixels pixelReader, x, y int, rang int, targetColor color.RGBA, colorRange int) int {
+	minX := pixels.img.Bounds().Min.X
+	minY := pixels.img.Bounds().Min.Y
 
-			if colorutil.ColorsCloseN(img.At(minX+x+i, minY+y+j), targetColor, colorRange) {
-				return true
+	count := 0
+	for j := -rang; j <= rang; j++ {
+		for i := -rang; i <= rang; i++ {
+			r, g, b := pixels.at(minX+x+i, minY+y+j)
+			if dist8(r, g, b, targetColor.R, targetColor.G, targetColor.B) < colorRange {
+				count++
 			}
 		}
 	}
-	return false
+	return count
 }
 
 func diffColor(img image.Image, x, y int, x2, y2 int) int {
@@ -0,0 +1,121 @@
+package identify_test
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+
+	"minego/internal/cell"
+	"minego/internal/degrade"
+	"minego/internal/identify"
+	"minego/internal/imgpos"
+	"minego/internal/render"
+)
+
+// benchBoard 渲染 16x30（高级）盘面，返回图像与网格线
+func benchBoard(tb testing.TB) (*image.RGBA, []int, []int) {
+	tb.Helper()
+	truth := degrade.SampleBoard(16, 30, 99, 1)
+	horizontalLines, verticalLines := render.Lines(16, 30, render.DefaultCellSize)
+	return render.Board(truth, render.DefaultCellSize), horizontalLines, verticalLines
+}
+
+func TestColorRecognizerDoesNotAllocate(t *testing.T) {
+	board, horizontalLines, verticalLines := benchBoard(t)
+	var recognizer identify.ColorRecognizer
+	for i := range len(horizontalLines) - 1 {
+		for j := range len(verticalLines) - 1 {
+			x := (verticalLines[j] + verticalLines[j+1]) / 2
+			y := (horizontalLines[i] + horizontalLines[i+1]) / 2
+			width := verticalLines[j+1] - verticalLines[j]
+			hight := horizontalLines[i+1] - horizontalLines[i]
+			allocs := testing.AllocsPerRun(10, func() {
+				recognizer.RecognizeWithConfidence(board, x, y, width, hight)
+			})
+			if allocs != 0 {
+				t.Fatalf("第 %d 行第 %d 列识别分配 %.0f 次", i+1, j+1, allocs)
+			}
+		}
+	}
+}
+
+func benchmarkIdentify(b *testing.B, recognizer identify.Recognizer) {
+	board, horizontalLines, verticalLines := benchBoard(b)
+	boardPos := imgpos.NewImageWithOffset(board, image.Point{})
+	b.ReportAllocs()
+	for b.Loop() {
+		identify.IdentifyWith(recognizer, boardPos, horizontalLines, verticalLines)
+	}
+}
+
+func BenchmarkIdentifyColor(b *testing.B) {
+	benchmarkIdentify(b, identify.ColorRecognizer{})
+}
+
+func BenchmarkIdentifyTemplate(b *testing.B) {
+	benchmarkIdentify(b, degrade.RenderedTemplates(render.DefaultCellSize))
+}
+
+func BenchmarkIdentifyShape(b *testing.B) {
+	benchmarkIdentify(b, identify.ShapeRecognizer{})
+}
+
+// BenchmarkTrackerUnchanged 画面没有变化时的增量识别
+func BenchmarkTrackerUnchanged(b *testing.B) {
+	board, horizontalLines, verticalLines := benchBoard(b)
+	boardPos := imgpos.NewImageWithOffset(board, image.Point{})
+	tracker := identify.NewTracker(identify.ColorRecognizer{}, horizontalLines, verticalLines)
+	tracker.Update(boardPos)
+	b.ReportAllocs()
+	for b.Loop() {
+		tracker.Update(boardPos)
+	}
+}
+
+// occludedBoard 在第 i 行第 j 列的格子上画一块与任何状态都不相像的灰色遮挡物
+func occludedBoard(tb testing.TB, i, j int) (*image.RGBA, []int, []int) {
+	board, horizontalLines, verticalLines := benchBoard(tb)
+	rect := image.Rect(verticalLines[j]+1, horizontalLines[i]+1, verticalLines[j+1], horizontalLines[i+1])
+	draw.Draw(board, rect, image.NewUniform(color.RGBA{90, 90, 90, 255}), image.Point{}, draw.Src)
+	return board, horizontalLines, verticalLines
+}
+
+func TestColorRecognizerUnobserved(t *testing.T) {
+	board, horizontalLines, verticalLines := occludedBoard(t, 3, 5)
+	cells := identify.IdentifyWith(identify.ColorRecognizer{}, imgpos.NewImageWithOffset(board, image.Point{}), horizontalLines, verticalLines)
+	if c := cells[3][5]; c.State != cell.Unobserved || c.Confidence >= identify.MinConfidence {
+		t.Errorf("被遮挡的格子识别为 %s，置信度 %.2f，应为无法观测且需要重新截图", identify.StateName(c.State), c.Confidence)
+	}
+	// 盘面本身含有无法观测的格子；颜色识别器没有 7 与 8 的特征色，同样识别为无法观测
+	truth := degrade.SampleBoard(16, 30, 99, 1)
+	for i, row := range cells {
+		for j, c := range row {
+			if want := truth[i][j].State; want == cell.Unobserved || want > cell.Number6 {
+				continue
+			}
+			if (i != 3 || j != 5) && c.State == cell.Unobserved {
+				t.Errorf("第 %d 行第 %d 列没有遮挡，却识别为无法观测", i+1, j+1)
+			}
+		}
+	}
+}
+
+func TestColorRecognizerFlagWithMineColoredPole(t *testing.T) {
+	// 许多客户端的旗杆与底座和地雷同为黑色
+	pole := render.FlagPoleColor
+	render.FlagPoleColor = identify.MineColor
+	defer func() { render.FlagPoleColor = pole }()
+
+	truth := degrade.SampleBoard(16, 30, 99, 1)
+	board := render.Board(truth, render.DefaultCellSize)
+	horizontalLines, verticalLines := render.Lines(16, 30, render.DefaultCellSize)
+	cells := identify.IdentifyWith(identify.ColorRecognizer{}, imgpos.NewImageWithOffset(board, image.Point{}), horizontalLines, verticalLines)
+	for i, row := range truth {
+		for j, c := range row {
+			if c.State == cell.Flagged && cells[i][j].State != cell.Flagged {
+				t.Errorf("第 %d 行第 %d 列的旗帜识别为 %s", i+1, j+1, identify.StateName(cells[i][j].State))
+			}
+		}
+	}
+}
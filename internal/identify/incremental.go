@@ -0,0 +1,105 @@
+package identify
+
+import (
+	"image"
+
+	"minego/internal/cell"
+	"minego/internal/imgpos"
+)
+
+// Tracker 在多次截图之间增量识别同一块雷区：保留上一帧每个格子的像素哈希与识别结果，
+// 只重新识别像素发生变化的格子。网格线在两次截图之间必须保持不变
+type Tracker struct {
+	Recognizer      Recognizer
+	horizontalLines []int
+	verticalLines   []int
+	cells           [][]cell.GridCell
+	hashes          [][]uint64
+}
+
+// NewTracker 创建增量识别器，recognizer 为 nil 时使用 DefaultRecognizer
+func NewTracker(recognizer Recognizer, horizontalLines, verticalLines []int) *Tracker {
+	return &Tracker{Recognizer: recognizer, horizontalLines: horizontalLines, verticalLines: verticalLines}
+}
+
+// Update 识别新截图，返回完整盘面（调用方可随意修改）以及本次重新识别的格子坐标（X 为列，Y 为行）。
+// 第一次调用识别全部格子；之后只重新识别像素哈希变化的格子，以及上次置信度低于 MinConfidence 的格子
+func (t *Tracker) Update(imgpos *imgpos.ImageWithOffset) ([][]cell.GridCell, []image.Point) {
+	recognizer := t.Recognizer
+	if recognizer == nil {
+		recognizer = DefaultRecognizer
+	}
+	rects := cellRects(t.horizontalLines, t.verticalLines)
+	cols := len(t.verticalLines) - 1
+
+	changed := make([]image.Point, 0)
+	if t.cells == nil {
+		t.cells = IdentifyWith(recognizer, imgpos, t.horizontalLines, t.verticalLines)
+		t.hashes = make([][]uint64, len(t.cells))
+		for i := range t.cells {
+			t.hashes[i] = make([]uint64, cols)
+			for j := range t.hashes[i] {
+				t.hashes[i][j] = cellHash(imgpos.Image, rects[i*cols+j])
+				changed = append(changed, image.Point{X: j, Y: i})
+			}
+		}
+		return t.snapshot(), changed
+	}
+
+	pixels := newPixelReader(imgpos.Image)
+	for i := range t.cells {
+		for j := range t.cells[i] {
+			hash := cellHash(imgpos.Image, rects[i*cols+j])
+			if hash == t.hashes[i][j] && t.cells[i][j].Confidence >= MinConfidence {
+				continue
+			}
+			t.hashes[i][j] = hash
+			t.cells[i][j] = identifyCell(recognizer, imgpos, pixels, t.horizontalLines, t.verticalLines, i, j)
+			changed = append(changed, image.Point{X: j, Y: i})
+		}
+	}
+	return t.snapshot(), changed
+}
+
+// Reset 丢弃保留的结果，下次 Update 重新识别全部格子
+func (t *Tracker) Reset() {
+	t.cells, t.hashes = nil, nil
+}
+
+// snapshot 返回盘面的副本，使调用方（例如 Refine）的修改不影响下一次比较
+func (t *Tracker) snapshot() [][]cell.GridCell {
+	cells := make([][]cell.GridCell, len(t.cells))
+	for i := range t.cells {
+		cells[i] = make([]cell.GridCell, len(t.cells[i]))
+		copy(cells[i], t.cells[i])
+	}
+	return cells
+}
+
+// cellHash 返回 rect（相对图像左上角）内像素的 FNV-1a 哈希，每个像素的 RGB 分量合并为一个字参与运算
+func cellHash(img image.Image, rect image.Rectangle) uint64 {
+	const (
+		offset64 = 14695981039346656037
+		prime64  = 1099511628211
+	)
+	hash := uint64(offset64)
+	origin := img.Bounds().Min
+	rect = rect.Add(origin).Intersect(img.Bounds())
+	if rgba, ok := img.(*image.RGBA); ok {
+		for y := rect.Min.Y; y < rect.Max.Y; y++ {
+			row := rgba.Pix[rgba.PixOffset(rect.Min.X, y):rgba.PixOffset(rect.Max.X, y)]
+			for k := 0; k+3 < len(row); k += 4 {
+				hash = (hash ^ (uint64(row[k]) | uint64(row[k+1])<<8 | uint64(row[k+2])<<16)) * prime64
+			}
+		}
+		return hash
+	}
+	pixels := newPixelReader(img)
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			r, g, b := pixels.at(x, y)
+			hash = (hash ^ (uint64(r) | uint64(g)<<8 | uint64(b)<<16)) * prime64
+		}
+	}
+	return hash
+}
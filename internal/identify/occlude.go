@@ -0,0 +1,47 @@
+package identify
+
+import (
+	"image"
+
+	"minego/internal/cell"
+)
+
+// Occlude 把屏幕区域与 rect 相交的格子标记为 cell.Unobserved（例如被鼠标指针或提示框遮挡），
+// 返回标记的格子数。rect 为屏幕坐标
+func Occlude(cells [][]cell.GridCell, rect image.Rectangle) int {
+	count := 0
+	for i := range cells {
+		for j := range cells[i] {
+			c := &cells[i][j]
+			center := c.ScreenPos()
+			area := image.Rect(center.X-c.Width/2, center.Y-c.Hight/2, center.X+(c.Width+1)/2, center.Y+(c.Hight+1)/2)
+			if !area.Overlaps(rect) {
+				continue
+			}
+			c.State, c.Confidence = cell.Unobserved, 0
+			count++
+		}
+	}
+	return count
+}
+
+// Unobserved 返回无法观测的格子数及其所在的最小行列范围（X 为列，Y 为行，Max 不含）
+func Unobserved(cells [][]cell.GridCell) (int, image.Rectangle) {
+	count := 0
+	var extent image.Rectangle
+	for i := range cells {
+		for j := range cells[i] {
+			if cells[i][j].State != cell.Unobserved {
+				continue
+			}
+			r := image.Rect(j, i, j+1, i+1)
+			if count == 0 {
+				extent = r
+			} else {
+				extent = extent.Union(r)
+			}
+			count++
+		}
+	}
+	return count, extent
+}
@@ -0,0 +1,34 @@
+package identify
+
+import "image"
+
+// pixelReader 按 8 位分量读取像素。*image.RGBA 直接访问 Pix，
+// 避免每个像素一次接口调用与 color.Color 分配；其他图像类型退回 At
+type pixelReader struct {
+	img  image.Image
+	rgba *image.RGBA
+}
+
+func newPixelReader(img image.Image) pixelReader {
+	rgba, _ := img.(*image.RGBA)
+	return pixelReader{img: img, rgba: rgba}
+}
+
+// at 返回绝对坐标 (x, y) 处像素的 RGB 分量，范围外返回黑色（与 At 的零值一致）
+func (p pixelReader) at(x, y int) (uint8, uint8, uint8) {
+	if p.rgba != nil {
+		if !(image.Point{X: x, Y: y}).In(p.rgba.Rect) {
+			return 0, 0, 0
+		}
+		i := p.rgba.PixOffset(x, y)
+		s := p.rgba.Pix[i : i+3 : i+3]
+		return s[0], s[1], s[2]
+	}
+	r, g, b, _ := p.img.At(x, y).RGBA()
+	return uint8(r >> 8), uint8(g >> 8), uint8(b >> 8)
+}
+
+// dist8 8 位分量的 L1 颜色距离
+func dist8(r1, g1, b1, r2, g2, b2 uint8) int {
+	return absDiff(int(r1), int(r2)) + absDiff(int(g1), int(g2)) + absDiff(int(b1), int(b2))
+}
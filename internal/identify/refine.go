@@ -0,0 +1,67 @@
+package identify
+
+import (
+	"image"
+	"time"
+
+	"minego/internal/cell"
+)
+
+var (
+	MinConfidence  = 0.5                   // 置信度低于该值的格子需要重新截图识别
+	RecaptureDelay = 30 * time.Millisecond // 两次重新截图之间的等待，让重绘中的格子稳定下来
+)
+
+// RefineStats 一次重新识别的统计
+type RefineStats struct {
+	Doubtful  int // 初次识别置信度不足的格子数
+	Recovered int // 重新识别后置信度达标的格子数
+	Ambiguous int // 多次重试后仍不确定、已置为 cell.Unobserved 的格子数
+}
+
+// Refine 对置信度低于 MinConfidence 的格子重新截图，只重新识别这些格子并保留置信度最高的结果。
+// capture 返回与原识别相同区域、相同坐标系的新截图。重试 attempts 次后仍不确定的格子置为 cell.Unobserved，
+// 这样求解器既不会把可能读错的数字当作约束，也不会点击它
+func Refine(cells [][]cell.GridCell, capture func() (image.Image, error), attempts int) (RefineStats, error) {
+	return RefineWith(DefaultRecognizer, cells, capture, attempts)
+}
+
+// RefineWith 使用指定识别器执行 Refine
+func RefineWith(recognizer Recognizer, cells [][]cell.GridCell, capture func() (image.Image, error), attempts int) (RefineStats, error) {
+	doubtful := make([]*cell.GridCell, 0)
+	for i := range cells {
+		for j := range cells[i] {
+			if cells[i][j].Confidence < MinConfidence {
+				doubtful = append(doubtful, &cells[i][j])
+			}
+		}
+	}
+	stats := RefineStats{Doubtful: len(doubtful)}
+
+	for attempt := 0; attempt < attempts && len(doubtful) > 0; attempt++ {
+		time.Sleep(RecaptureDelay)
+		img, err := capture()
+		if err != nil {
+			return stats, err
+		}
+		remaining := doubtful[:0]
+		for _, c := range doubtful {
+			state, confidence := recognizeWithConfidence(recognizer, img, c.X, c.Y, c.Width, c.Hight)
+			if confidence > c.Confidence {
+				c.State, c.Confidence = state, confidence
+			}
+			if c.Confidence < MinConfidence {
+				remaining = append(remaining, c)
+			} else {
+				stats.Recovered++
+			}
+		}
+		doubtful = remaining
+	}
+
+	for _, c := range doubtful {
+		c.State = cell.Unobserved
+	}
+	stats.Ambiguous = len(doubtful)
+	return stats, nil
+}
@@ -0,0 +1,92 @@
+package identify
+
+import (
+	"image"
+	"time"
+)
+
+var (
+	SettleInterval  = 15 * time.Millisecond  // 判断动画时相邻两帧的间隔
+	SettleTimeout   = 300 * time.Millisecond // 等待动画结束的最长时间，超时后使用最后一帧
+	SettleTolerance = 4.0                    // 单元格平均每分量差异不超过该值（8 位）时视为未变化
+)
+
+// SettleStats 一次等待动画的统计
+type SettleStats struct {
+	Frames    int           // 截图次数
+	Changed   int           // 与上一次稳定截图相比发生变化的格子数
+	Animating int           // 超时时仍在变化的格子数，正常结束时为 0
+	Waited    time.Duration // 从第一次截图到返回的时间
+}
+
+// Settle 截图并等待 Windows 7 翻开、插旗等动画结束。
+// 与上一次稳定截图 previous 相比发生变化的格子需要在相邻两帧中保持不变才视为稳定，
+// previous 为 nil 时检查全部格子。超时后返回最后一帧，未稳定的格子数记录在 Animating 中
+func Settle(capture func() (image.Image, error), previous image.Image, horizontalLines, verticalLines []int) (image.Image, SettleStats, error) {
+	start := time.Now()
+	frame, err := capture()
+	if err != nil {
+		return nil, SettleStats{}, err
+	}
+	stats := SettleStats{Frames: 1}
+
+	changed := make([]image.Rectangle, 0)
+	for _, rect := range cellRects(horizontalLines, verticalLines) {
+		if previous == nil || cellDiff(previous, frame, rect) > SettleTolerance {
+			changed = append(changed, rect)
+		}
+	}
+	stats.Changed = len(changed)
+
+	for len(changed) > 0 {
+		if time.Since(start) >= SettleTimeout {
+			stats.Animating = len(changed)
+			break
+		}
+		time.Sleep(SettleInterval)
+		next, err := capture()
+		if err != nil {
+			return frame, stats, err
+		}
+		stats.Frames++
+		animating := changed[:0]
+		for _, rect := range changed {
+			if cellDiff(frame, next, rect) > SettleTolerance {
+				animating = append(animating, rect)
+			}
+		}
+		frame, changed = next, animating
+	}
+	stats.Waited = time.Since(start)
+	return frame, stats, nil
+}
+
+// cellRects 返回各单元格内部（去掉网格线）相对图像左上角的范围
+func cellRects(horizontalLines, verticalLines []int) []image.Rectangle {
+	rects := make([]image.Rectangle, 0, max(len(horizontalLines)-1, 0)*max(len(verticalLines)-1, 0))
+	for i := 0; i+1 < len(horizontalLines); i++ {
+		for j := 0; j+1 < len(verticalLines); j++ {
+			rects = append(rects, image.Rect(verticalLines[j]+1, horizontalLines[i]+1, verticalLines[j+1], horizontalLines[i+1]))
+		}
+	}
+	return rects
+}
+
+// cellDiff 返回两幅图像在 rect（相对各自左上角）内的平均每分量差异，隔行隔列采样
+func cellDiff(a, b image.Image, rect image.Rectangle) float64 {
+	pa, pb := newPixelReader(a), newPixelReader(b)
+	oa, ob := a.Bounds().Min, b.Bounds().Min
+	sum, n := 0, 0
+	for y := rect.Min.Y; y < rect.Max.Y; y += 2 {
+		for x := rect.Min.X; x < rect.Max.X; x += 2 {
+			r1, g1, b1 := pa.at(oa.X+x, oa.Y+y)
+			r2, g2, b2 := pb.at(ob.X+x, ob.Y+y)
+			sum += dist8(r1, g1, b1, r2, g2, b2)
+			n++
+		}
+	}
+	if n == 0 {
+		return 0
+	}
+	return float64(sum) / float64(3*n)
+}
@@ -0,0 +1,347 @@
+package identify
+
+import (
+	"image"
+	"sort"
+
+	"minego/internal/cell"
+)
+
+const (
+	minInkContrast = 90   // 与背景的颜色距离（8 位 L1）至少达到该值才视为字形像素
+	minGlyphArea   = 0.03 // 字形连通域至少占裁剪区域的比例
+	minGlyphHight  = 0.3  // 字形高度至少占裁剪区域高度的比例
+	maxGlyphFill   = 0.65 // 字形像素占外接矩形的比例超过该值时视为实心图案而非数字
+	maxGlyphAspect = 0.92 // 字形宽高比超过该值时视为圆形等图案（地雷等）而非数字
+	shapeMargin    = 0.15 // 各项形状特征距判定阈值达到该值时视为完全确定
+)
+
+// ShapeRecognizer 基于字形形状的数字识别器，不依赖数字颜色，适用于所有数字同色的主题或色盲模式。
+// 先用 Base 识别：旗帜、"?"、踩雷、插错等有专属颜色的状态直接采用；
+// 否则在单元格内用连通域标记分割字形，按孔洞数、外接矩形比例与笔画投影判断 1-8。
+// 两者都认为是同一数字时置信度更高；与 Base 的数字不一致、或 Base 认为是覆盖格或地雷时
+// 采用字形结果并把置信度减半
+type ShapeRecognizer struct {
+	Base Recognizer // 为 nil 时使用 ColorRecognizer
+}
+
+// Recognize 实现 Recognizer 接口
+func (s ShapeRecognizer) Recognize(img image.Image, x, y, width, hight int) cell.CellState {
+	state, _ := s.RecognizeWithConfidence(img, x, y, width, hight)
+	return state
+}
+
+// RecognizeWithConfidence 实现 ConfidenceRecognizer 接口
+func (s ShapeRecognizer) RecognizeWithConfidence(img image.Image, x, y, width, hight int) (cell.CellState, float64) {
+	base := s.Base
+	if base == nil {
+		base = ColorRecognizer{}
+	}
+	state, confidence := recognizeWithConfidence(base, img, x, y, width, hight)
+	isNumber := state >= cell.Number1 && state <= cell.Number8
+	// 颜色识别器不认识的数字颜色（同色主题、7 与 8）会落到无法观测，同样需要按字形判断
+	if !isNumber && state != cell.Empty && state != cell.Unknown && state != cell.Mine && state != cell.Unobserved {
+		return state, confidence
+	}
+
+	digit, shapeConfidence, ok := RecognizeGlyph(CellCrop(img, x, y, width, hight))
+	switch {
+	case !ok && isNumber:
+		// 颜色命中数字但找不到字形，可能是噪点
+		return state, confidence / 2
+	case !ok:
+		return state, confidence
+	case digit == state:
+		return digit, 1 - (1-confidence)*(1-shapeConfidence)
+	case isNumber:
+		return digit, shapeConfidence / 2
+	case state == cell.Unknown || state == cell.Mine:
+		// 覆盖格与地雷上本不该有数字，字形可能来自图案或遮挡物，交给 Refine 重新确认
+		return digit, shapeConfidence / 2
+	default:
+		return digit, shapeConfidence
+	}
+}
+
+// RecognizeGlyph 在单元格图像中分割字形并按形状识别数字，ok 为 false 表示没有像数字的字形
+func RecognizeGlyph(crop image.Image) (cell.CellState, float64, bool) {
+	g, ok := segmentGlyph(crop)
+	if !ok {
+		return cell.Unknown, 0, false
+	}
+	return g.classify()
+}
+
+// glyph 分割出的字形，mask 覆盖外接矩形，true 表示属于该连通域
+type glyph struct {
+	w, h int
+	mask []bool
+	area int
+}
+
+func (g glyph) at(x, y int) bool {
+	return x >= 0 && y >= 0 && x < g.w && y < g.h && g.mask[y*g.w+x]
+}
+
+// segmentGlyph 以裁剪区域各分量的中位数为背景色（字形只占单元格的一小部分，且不受边缘模糊的网格线影响），
+// 标记与背景差异明显的像素，
+// 按 8 连通取不接触裁剪区域边缘（残留的网格线、相邻格子）的最大连通域作为字形
+func segmentGlyph(crop image.Image) (glyph, bool) {
+	b := crop.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w < 5 || h < 5 {
+		return glyph{}, false
+	}
+	pixels := newPixelReader(crop)
+
+	var channels [3][]uint8
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			r, g, bl := pixels.at(x, y)
+			channels[0], channels[1], channels[2] = append(channels[0], r), append(channels[1], g), append(channels[2], bl)
+		}
+	}
+	br, bg, bb := median8(channels[0]), median8(channels[1]), median8(channels[2])
+
+	dist := make([]int, w*h)
+	maxDist := 0
+	for y := range h {
+		for x := range w {
+			r, g, bl := pixels.at(b.Min.X+x, b.Min.Y+y)
+			d := dist8(r, g, bl, br, bg, bb)
+			dist[y*w+x] = d
+			maxDist = max(maxDist, d)
+		}
+	}
+	threshold := max(maxDist/2, minInkContrast)
+	ink := make([]bool, w*h)
+	for k, d := range dist {
+		ink[k] = d >= threshold
+	}
+
+	labels, areas := labelComponents(ink, w, h, true)
+	touching := make([]bool, len(areas))
+	for k, label := range labels {
+		if x, y := k%w, k/w; x == 0 || y == 0 || x == w-1 || y == h-1 {
+			touching[label] = true
+		}
+	}
+	best := 0
+	for label := 1; label < len(areas); label++ {
+		if !touching[label] && areas[label] > areas[best] {
+			best = label
+		}
+	}
+	if best == 0 || float64(areas[best]) < minGlyphArea*float64(w*h) {
+		return glyph{}, false
+	}
+
+	minX, minY, maxX, maxY := w, h, -1, -1
+	for k, label := range labels {
+		if label == best {
+			x, y := k%w, k/w
+			minX, minY = min(minX, x), min(minY, y)
+			maxX, maxY = max(maxX, x), max(maxY, y)
+		}
+	}
+	g := glyph{w: maxX - minX + 1, h: maxY - minY + 1, area: areas[best]}
+	if float64(g.h) < minGlyphHight*float64(h) {
+		return glyph{}, false
+	}
+	g.mask = make([]bool, g.w*g.h)
+	for y := range g.h {
+		for x := range g.w {
+			g.mask[y*g.w+x] = labels[(y+minY)*w+x+minX] == best
+		}
+	}
+	return g, true
+}
+
+// labelComponents 对 mask 中为 true 的像素做连通域标记，返回每个像素的标号（0 表示不属于任何连通域）
+// 以及每个标号的面积（下标 0 不使用）。eight 为 true 时按 8 连通，否则按 4 连通
+func labelComponents(mask []bool, w, h int, eight bool) ([]int, []int) {
+	labels := make([]int, len(mask))
+	areas := []int{0}
+	stack := make([]int, 0)
+	for start := range mask {
+		if !mask[start] || labels[start] != 0 {
+			continue
+		}
+		label := len(areas)
+		areas = append(areas, 0)
+		labels[start] = label
+		stack = append(stack[:0], start)
+		for len(stack) > 0 {
+			k := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			areas[label]++
+			x, y := k%w, k/w
+			for dy := -1; dy <= 1; dy++ {
+				for dx := -1; dx <= 1; dx++ {
+					if (dx == 0 && dy == 0) || (!eight && dx != 0 && dy != 0) {
+						continue
+					}
+					nx, ny := x+dx, y+dy
+					if nx < 0 || ny < 0 || nx >= w || ny >= h {
+						continue
+					}
+					n := ny*w + nx
+					if mask[n] && labels[n] == 0 {
+						labels[n] = label
+						stack = append(stack, n)
+					}
+				}
+			}
+		}
+	}
+	return labels, areas
+}
+
+// holes 返回字形中孔洞（4 连通、不接触外接矩形边缘的背景区域）的数量及各孔洞中心的相对纵坐标
+func (g glyph) holes() []float64 {
+	background := make([]bool, len(g.mask))
+	for k, v := range g.mask {
+		background[k] = !v
+	}
+	labels, areas := labelComponents(background, g.w, g.h, false)
+	open := make([]bool, len(areas))
+	sumY := make([]int, len(areas))
+	for k, label := range labels {
+		if label == 0 {
+			continue
+		}
+		x, y := k%g.w, k/g.w
+		if x == 0 || y == 0 || x == g.w-1 || y == g.h-1 {
+			open[label] = true
+		}
+		sumY[label] += y
+	}
+	minArea := max(1, g.area/40)
+	centers := make([]float64, 0)
+	for label := 1; label < len(areas); label++ {
+		if !open[label] && areas[label] >= minArea {
+			centers = append(centers, (float64(sumY[label])/float64(areas[label])+0.5)/float64(g.h))
+		}
+	}
+	return centers
+}
+
+// inkRatio 返回相对坐标 [x0, x1) × [y0, y1) 范围内字形像素的比例
+func (g glyph) inkRatio(x0, x1, y0, y1 float64) float64 {
+	ax, bx := int(x0*float64(g.w)), max(int(x1*float64(g.w)), int(x0*float64(g.w))+1)
+	ay, by := int(y0*float64(g.h)), max(int(y1*float64(g.h)), int(y0*float64(g.h))+1)
+	n, ink := 0, 0
+	for y := ay; y < by; y++ {
+		for x := ax; x < bx; x++ {
+			n++
+			if g.at(x, y) {
+				ink++
+			}
+		}
+	}
+	if n == 0 {
+		return 0
+	}
+	return float64(ink) / float64(n)
+}
+
+// classify 按形状特征判断数字：
+// 两个孔洞为 8；一个孔洞时孔在下半部为 6，否则为 4；
+// 没有孔洞时，贯穿整个高度的竖直笔画为 1（同时有中下部的横笔画则为开口的 4），其余按顶边、底边是否填满以及上下两段笔画偏左还是偏右区分 2、3、5、7
+func (g glyph) classify() (cell.CellState, float64, bool) {
+	fill := float64(g.area) / float64(g.w*g.h)
+	aspect := float64(g.w) / float64(g.h)
+	if fill > maxGlyphFill || aspect > maxGlyphAspect {
+		return cell.Unknown, 0, false
+	}
+	margin := func(value, threshold float64) float64 {
+		d := value - threshold
+		if d < 0 {
+			d = -d
+		}
+		return min(d/shapeMargin, 1)
+	}
+	confidence := min(margin(fill, maxGlyphFill), margin(aspect, maxGlyphAspect))
+
+	holes := g.holes()
+	switch len(holes) {
+	case 2:
+		return cell.Number8, confidence, true
+	case 1:
+		confidence = min(confidence, margin(holes[0], 0.55))
+		if holes[0] > 0.55 {
+			return cell.Number6, confidence, true
+		}
+		return cell.Number4, confidence, true
+	case 0:
+	default:
+		return cell.Unknown, 0, false
+	}
+
+	// 竖直笔画：某一列（中间 60% 范围内）几乎贯穿整个高度
+	stem := 0.0
+	for x := g.w / 5; x < g.w-g.w/5; x++ {
+		n := 0
+		for y := range g.h {
+			if g.at(x, y) {
+				n++
+			}
+		}
+		stem = max(stem, float64(n)/float64(g.h))
+	}
+	// 横笔画：中下部某一行几乎横跨整个宽度，与竖直笔画同时出现且左下角空白时为开口的 4
+	bar := 0.0
+	for y := g.h * 2 / 5; y < g.h*17/20; y++ {
+		bar = max(bar, g.inkRatio(0, 1, float64(y)/float64(g.h), float64(y+1)/float64(g.h)))
+	}
+	if corner := g.inkRatio(0, 0.4, 0.88, 1); stem >= 0.85 && bar >= 0.8 && corner < 0.2 {
+		return cell.Number4, min(confidence, margin(stem, 0.85), margin(bar, 0.8), margin(corner, 0.2)), true
+	}
+	// 1 的中段只有竖直笔画，大多数行的笔画都远窄于字形
+	widths := make([]float64, 0)
+	for y := g.h * 3 / 10; y < max(g.h*7/10, g.h*3/10+1); y++ {
+		widths = append(widths, g.inkRatio(0, 1, float64(y)/float64(g.h), float64(y+1)/float64(g.h)))
+	}
+	sort.Float64s(widths)
+	width := widths[len(widths)/2]
+	if (stem >= 0.85 || aspect < 0.45) && width < 0.55 {
+		return cell.Number1, min(confidence, max(margin(stem, 0.85), margin(aspect, 0.45)), margin(width, 0.55)), true
+	}
+	confidence = min(confidence, margin(stem, 0.85))
+
+	top := g.inkRatio(0, 1, 0, 0.12)
+	bottom := g.inkRatio(0, 1, 0.88, 1)
+	upperLeft := g.inkRatio(0, 0.4, 0.15, 0.4)
+	upperRight := g.inkRatio(0.6, 1, 0.15, 0.4)
+	lowerLeft := g.inkRatio(0, 0.4, 0.6, 0.85)
+	lowerRight := g.inkRatio(0.6, 1, 0.6, 0.85)
+	upper := upperRight - upperLeft // 上段笔画偏右为正
+	lower := lowerRight - lowerLeft // 下段笔画偏右为正
+	confidence = min(confidence, margin(upper, 0), margin(lower, 0))
+
+	switch {
+	case upper < 0:
+		return cell.Number5, confidence, true
+	case lower > 0:
+		return cell.Number3, confidence, true
+	case bottom >= 0.6:
+		return cell.Number2, min(confidence, margin(bottom, 0.6)), true
+	default:
+		return cell.Number7, min(confidence, margin(bottom, 0.6), margin(top, 0.6)), true
+	}
+}
+
+// median8 按计数排序返回 8 位分量的中位数
+func median8(values []uint8) uint8 {
+	var counts [256]int
+	for _, v := range values {
+		counts[v]++
+	}
+	half, n := len(values)/2, 0
+	for v, c := range counts {
+		if n += c; n > half {
+			return uint8(v)
+		}
+	}
+	return 0
+}
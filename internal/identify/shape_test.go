@@ -0,0 +1,84 @@
+package identify_test
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"minego/internal/cell"
+	"minego/internal/identify"
+	"minego/internal/imgpos"
+	"minego/internal/render"
+)
+
+// fixedRecognizer 总是以完全的置信度给出同一状态，用作 ShapeRecognizer 的 Base
+type fixedRecognizer cell.CellState
+
+func (f fixedRecognizer) Recognize(img image.Image, x, y, width, hight int) cell.CellState {
+	return cell.CellState(f)
+}
+
+func (f fixedRecognizer) RecognizeWithConfidence(img image.Image, x, y, width, hight int) (cell.CellState, float64) {
+	return cell.CellState(f), 1
+}
+
+func TestShapeRecognizerDoubtsDigitsOverCoveredOrMine(t *testing.T) {
+	truth := [][]cell.GridCell{{{State: cell.Number3}}}
+	board := render.Board(truth, render.DefaultCellSize)
+	horizontalLines, verticalLines := render.Lines(1, 1, render.DefaultCellSize)
+	x := (verticalLines[0] + verticalLines[1]) / 2
+	y := (horizontalLines[0] + horizontalLines[1]) / 2
+	width, hight := verticalLines[1]-verticalLines[0], horizontalLines[1]-horizontalLines[0]
+
+	state, sure := identify.ShapeRecognizer{Base: fixedRecognizer(cell.Empty)}.RecognizeWithConfidence(board, x, y, width, hight)
+	if state != cell.Number3 || sure < identify.MinConfidence {
+		t.Fatalf("空白格上的字形识别为 %s，置信度 %.2f", identify.StateName(state), sure)
+	}
+	for _, base := range []cell.CellState{cell.Unknown, cell.Mine} {
+		state, confidence := identify.ShapeRecognizer{Base: fixedRecognizer(base)}.RecognizeWithConfidence(board, x, y, width, hight)
+		if state != cell.Number3 || confidence != sure/2 {
+			t.Errorf("Base 识别为 %s 时结果为 %s，置信度 %.2f，应为 3 且置信度减半为 %.2f",
+				identify.StateName(base), identify.StateName(state), confidence, sure/2)
+		}
+	}
+}
+
+// recolorDigits 把数字格中除背景外的像素（即字形）改为同一颜色，模拟所有数字同色的主题
+func recolorDigits(board *image.RGBA, truth [][]cell.GridCell, horizontalLines, verticalLines []int, ink color.RGBA) {
+	for i, row := range truth {
+		for j, c := range row {
+			if c.State < cell.Number1 || c.State > cell.Number8 {
+				continue
+			}
+			for y := horizontalLines[i] + 1; y < horizontalLines[i+1]; y++ {
+				for x := verticalLines[j] + 1; x < verticalLines[j+1]; x++ {
+					if board.RGBAAt(x, y) != render.RevealedColor {
+						board.SetRGBA(x, y, ink)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestShapeRecognizerSingleColorDigits(t *testing.T) {
+	row := make([]cell.GridCell, 8)
+	for j := range row {
+		row[j] = cell.GridCell{State: cell.Number1 + cell.CellState(j), Position: image.Point{X: j}}
+	}
+	truth := [][]cell.GridCell{row}
+	horizontalLines, verticalLines := render.Lines(1, len(row), render.DefaultCellSize)
+
+	for name, ink := range map[string]color.RGBA{"原色": {}, "黑色": {0, 0, 0, 255}, "青色": {0, 128, 128, 255}} {
+		board := render.Board(truth, render.DefaultCellSize)
+		if ink.A != 0 {
+			recolorDigits(board, truth, horizontalLines, verticalLines, ink)
+		}
+		cells := identify.IdentifyWith(identify.ShapeRecognizer{}, imgpos.NewImageWithOffset(board, image.Point{}), horizontalLines, verticalLines)
+		for j, c := range cells[0] {
+			if want := row[j].State; c.State != want || c.Confidence < identify.MinConfidence {
+				t.Errorf("%s数字 %s 识别为 %s，置信度 %.2f", name, identify.StateName(want), identify.StateName(c.State), c.Confidence)
+			}
+		}
+	}
+}
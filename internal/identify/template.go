@@ -0,0 +1,356 @@
+package identify
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"minego/internal/cell"
+)
+
+const (
+	templateSize         = 16  // 模板统一缩放到的边长
+	maxTemplatesPerState = 3   // 从盘面提取模板时每种状态最多保留的样本数
+	templateMargin       = 0.1 // 最佳与次佳状态相似度之差达到该值时视为完全确定
+)
+
+// MinTemplateScore 与所有模板的相似度都低于该值时单元格被判为 cell.Unobserved（例如被深色窗口遮挡）
+var MinTemplateScore = 0.7
+
+// stateFileNames 模板文件名与状态的对应关系，文件名形如 "3.png" 或 "3_win7.png"
+var stateFileNames = map[cell.CellState]string{
+	cell.Unobserved:   "unobserved",
+	cell.QuestionMark: "question",
+	cell.Exploded:     "exploded",
+	cell.Misflag:      "misflag",
+	cell.Mine:         "mine",
+	cell.Flagged:      "flagged",
+	cell.Unknown:      "unknown",
+	cell.Locked:       "locked",
+	cell.Empty:        "empty",
+	cell.Number1:      "1",
+	cell.Number2:      "2",
+	cell.Number3:      "3",
+	cell.Number4:      "4",
+	cell.Number5:      "5",
+	cell.Number6:      "6",
+	cell.Number7:      "7",
+	cell.Number8:      "8",
+}
+
+// Template 单个参考模板
+type Template struct {
+	State  cell.CellState
+	Image  *image.RGBA // 原始单元格图像，保存模板时原样写出
+	vector []float64   // 缩放到 templateSize 后以 128 为中心的 RGB 向量，已归一化为单位长度
+}
+
+// TemplateClassifier 模板匹配识别器
+// 将单元格缩放到统一尺寸，与每个模板计算以 128 为中心的归一化互相关，取得分最高的状态。
+// 以 128 而非图像均值为中心，使纯色的覆盖格与空白格仍可区分
+type TemplateClassifier struct {
+	Templates []Template
+}
+
+// NewTemplateClassifier 创建空的模板识别器
+func NewTemplateClassifier() *TemplateClassifier {
+	return &TemplateClassifier{}
+}
+
+// Add 以单元格图像添加一个模板
+func (c *TemplateClassifier) Add(state cell.CellState, crop image.Image) {
+	b := crop.Bounds()
+	copied := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(copied, copied.Bounds(), crop, b.Min, draw.Src)
+	c.Templates = append(c.Templates, Template{State: state, Image: copied, vector: templateVector(copied)})
+}
+
+// Classify 返回与单元格图像最相似的状态及相似度（-1 到 1）
+func (c *TemplateClassifier) Classify(crop image.Image) (cell.CellState, float64) {
+	best, bestScore, _ := c.classify(crop)
+	return best, bestScore
+}
+
+// classify 额外返回其他状态中的最高相似度，用于估计置信度
+func (c *TemplateClassifier) classify(crop image.Image) (cell.CellState, float64, float64) {
+	vector := templateVector(crop)
+	scores := make(map[cell.CellState]float64)
+	for _, t := range c.Templates {
+		score := dot(vector, t.vector)
+		if old, ok := scores[t.State]; !ok || score > old {
+			scores[t.State] = score
+		}
+	}
+	best, bestScore, second := cell.Unknown, math.Inf(-1), math.Inf(-1)
+	for state, score := range scores {
+		switch {
+		case score > bestScore || (score == bestScore && state < best):
+			best, bestScore, second = state, score, max(second, bestScore)
+		case score > second:
+			second = score
+		}
+	}
+	return best, bestScore, second
+}
+
+// Recognize 实现 Recognizer 接口，没有模板时返回 cell.Unknown
+func (c *TemplateClassifier) Recognize(img image.Image, x, y, width, hight int) cell.CellState {
+	state, _ := c.RecognizeWithConfidence(img, x, y, width, hight)
+	return state
+}
+
+// RecognizeWithConfidence 实现 ConfidenceRecognizer 接口，
+// 置信度由最佳状态与次佳状态的相似度之差换算，只有一种状态的模板时视为完全确定；
+// 最佳相似度低于 MinTemplateScore 时返回 cell.Unobserved，置信度为 0，由 Refine 重新截图
+func (c *TemplateClassifier) RecognizeWithConfidence(img image.Image, x, y, width, hight int) (cell.CellState, float64) {
+	if len(c.Templates) == 0 {
+		return cell.Unknown, 0
+	}
+	state, best, second := c.classify(CellCrop(img, x, y, width, hight))
+	if best < MinTemplateScore {
+		return cell.Unobserved, 0
+	}
+	if math.IsInf(second, -1) {
+		return state, 1
+	}
+	return state, min(max((best-second)/templateMargin, 0), 1)
+}
+
+// CellCrop 返回以 (x, y) 为中心的单元格内部区域，去掉边缘的网格线
+func CellCrop(img image.Image, x, y, width, hight int) image.Image {
+	b := img.Bounds()
+	halfW := width/2 - max(width/12, 1)
+	halfH := hight/2 - max(hight/12, 1)
+	rect := image.Rect(b.Min.X+x-halfW, b.Min.Y+y-halfH, b.Min.X+x+halfW, b.Min.Y+y+halfH).Intersect(b)
+	if sub, ok := img.(interface {
+		SubImage(image.Rectangle) image.Image
+	}); ok {
+		return sub.SubImage(rect)
+	}
+	crop := image.NewRGBA(image.Rect(0, 0, rect.Dx(), rect.Dy()))
+	draw.Draw(crop, crop.Bounds(), img, rect.Min, draw.Src)
+	return crop
+}
+
+// ExtractTemplates 根据已知标注从盘面图像中提取模板，每种状态最多保留 maxTemplatesPerState 个
+func ExtractTemplates(img image.Image, horizontalLines, verticalLines []int, labels [][]cell.GridCell) *TemplateClassifier {
+	c := NewTemplateClassifier()
+	counts := make(map[cell.CellState]int)
+	for i := range min(len(labels), len(horizontalLines)-1) {
+		for j := range min(len(labels[i]), len(verticalLines)-1) {
+			state := labels[i][j].State
+			if counts[state] >= maxTemplatesPerState {
+				continue
+			}
+			counts[state]++
+			x := (verticalLines[j] + verticalLines[j+1]) / 2
+			y := (horizontalLines[i] + horizontalLines[i+1]) / 2
+			c.Add(state, CellCrop(img, x, y, verticalLines[j+1]-verticalLines[j], horizontalLines[i+1]-horizontalLines[i]))
+		}
+	}
+	return c
+}
+
+// LoadTemplates 从目录加载 PNG 模板，文件名前缀（第一个 "_" 或扩展名之前）决定状态，
+// 例如 "empty.png"、"7_xp.png"；无法识别的文件会被忽略
+func LoadTemplates(dir string) (*TemplateClassifier, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.png"))
+	if err != nil {
+		return nil, fmt.Errorf("读取模板目录失败: %v", err)
+	}
+	sort.Strings(files)
+
+	c := NewTemplateClassifier()
+	for _, file := range files {
+		base := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+		prefix, _, _ := strings.Cut(base, "_")
+		state, ok := StateFromFileName(prefix)
+		if !ok {
+			continue
+		}
+		img, err := loadPNG(file)
+		if err != nil {
+			return nil, err
+		}
+		c.Add(state, img)
+	}
+	if len(c.Templates) == 0 {
+		return nil, fmt.Errorf("目录 %s 中没有可用的模板", dir)
+	}
+	return c, nil
+}
+
+// SaveTemplates 将所有模板以 "<状态>_<序号>.png" 的形式写入目录
+func (c *TemplateClassifier) SaveTemplates(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("创建模板目录失败: %v", err)
+	}
+	counts := make(map[cell.CellState]int)
+	for _, t := range c.Templates {
+		name := fmt.Sprintf("%s_%d.png", StateFileName(t.State), counts[t.State])
+		counts[t.State]++
+		file, err := os.Create(filepath.Join(dir, name))
+		if err != nil {
+			return fmt.Errorf("创建模板文件失败: %v", err)
+		}
+		err = png.Encode(file, t.Image)
+		file.Close()
+		if err != nil {
+			return fmt.Errorf("写入模板文件失败: %v", err)
+		}
+	}
+	return nil
+}
+
+// StateFileName 返回状态对应的模板文件名前缀
+func StateFileName(state cell.CellState) string {
+	if name, ok := stateFileNames[state]; ok {
+		return name
+	}
+	return fmt.Sprintf("state%d", int(state))
+}
+
+// StateFileNames 返回所有状态的文件名前缀，按状态排序
+func StateFileNames() []string {
+	states := make([]cell.CellState, 0, len(stateFileNames))
+	for state := range stateFileNames {
+		states = append(states, state)
+	}
+	sort.Slice(states, func(a, b int) bool { return states[a] < states[b] })
+	names := make([]string, len(states))
+	for k, state := range states {
+		names[k] = stateFileNames[state]
+	}
+	return names
+}
+
+// StateFromFileName StateFileName 的逆映射
+func StateFromFileName(name string) (cell.CellState, bool) {
+	for state, n := range stateFileNames {
+		if n == name {
+			return state, true
+		}
+	}
+	return 0, false
+}
+
+// Diverse 返回每种状态最多保留 perState 个模板的新识别器。
+// 先选最接近该状态平均外观的样本，再依次选取与已选模板最不相似的样本，使悬停高亮、渐变等不同外观都有代表
+func (c *TemplateClassifier) Diverse(perState int) *TemplateClassifier {
+	byState := make(map[cell.CellState][]Template)
+	states := make([]cell.CellState, 0)
+	for _, t := range c.Templates {
+		if _, ok := byState[t.State]; !ok {
+			states = append(states, t.State)
+		}
+		byState[t.State] = append(byState[t.State], t)
+	}
+
+	out := NewTemplateClassifier()
+	for _, state := range states {
+		candidates := byState[state]
+		mean := make([]float64, len(candidates[0].vector))
+		for _, t := range candidates {
+			for k, v := range t.vector {
+				mean[k] += v
+			}
+		}
+		first, best := 0, math.Inf(-1)
+		for k, t := range candidates {
+			if score := dot(t.vector, mean); score > best {
+				first, best = k, score
+			}
+		}
+
+		// closest[k] 为候选 k 与已选模板的最大相似度
+		closest := make([]float64, len(candidates))
+		for k := range closest {
+			closest[k] = math.Inf(-1)
+		}
+		next := first
+		for range min(perState, len(candidates)) {
+			out.Templates = append(out.Templates, candidates[next])
+			for k, t := range candidates {
+				closest[k] = max(closest[k], dot(t.vector, candidates[next].vector))
+			}
+			next, best = -1, math.Inf(1)
+			for k, sim := range closest {
+				if sim < best {
+					next, best = k, sim
+				}
+			}
+		}
+	}
+	return out
+}
+
+func dot(a, b []float64) float64 {
+	sum := 0.0
+	for k := range a {
+		sum += a[k] * b[k]
+	}
+	return sum
+}
+
+// templateVector 将图像按区域平均缩放到 templateSize，返回以 128 为中心并归一化的 RGB 向量。
+// 图像为空（单元格在截图之外或网格线退化）时返回零向量，与任何模板的相似度都是 0
+func templateVector(img image.Image) []float64 {
+	b := img.Bounds()
+	if b.Empty() {
+		return make([]float64, templateSize*templateSize*3)
+	}
+	pixels := newPixelReader(img)
+	vector := make([]float64, 0, templateSize*templateSize*3)
+	for ty := range templateSize {
+		y0 := b.Min.Y + ty*b.Dy()/templateSize
+		y1 := max(b.Min.Y+(ty+1)*b.Dy()/templateSize, y0+1)
+		for tx := range templateSize {
+			x0 := b.Min.X + tx*b.Dx()/templateSize
+			x1 := max(b.Min.X+(tx+1)*b.Dx()/templateSize, x0+1)
+			var sum [3]float64
+			n := 0.0
+			for y := y0; y < y1; y++ {
+				for x := x0; x < x1; x++ {
+					r, g, bl := pixels.at(x, y)
+					sum[0] += float64(r)
+					sum[1] += float64(g)
+					sum[2] += float64(bl)
+					n++
+				}
+			}
+			for ch := range sum {
+				vector = append(vector, sum[ch]/n-128)
+			}
+		}
+	}
+
+	norm := 0.0
+	for _, v := range vector {
+		norm += v * v
+	}
+	if norm = math.Sqrt(norm); norm > 0 {
+		for k := range vector {
+			vector[k] /= norm
+		}
+	}
+	return vector
+}
+
+func loadPNG(path string) (image.Image, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("打开模板文件失败: %v", err)
+	}
+	defer file.Close()
+	img, err := png.Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("解码模板 %s 失败: %v", path, err)
+	}
+	return img, nil
+}
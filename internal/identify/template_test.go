@@ -0,0 +1,36 @@
+package identify_test
+
+import (
+	"image"
+	"testing"
+
+	"minego/internal/cell"
+	"minego/internal/degrade"
+	"minego/internal/identify"
+	"minego/internal/render"
+)
+
+func TestTemplateClassifierEmptyCrop(t *testing.T) {
+	templates := degrade.RenderedTemplates(render.DefaultCellSize)
+	// 空图像与任何模板的相似度都是 0，而不是与截图外的黑色像素比较
+	if _, score := templates.Classify(image.NewRGBA(image.Rectangle{})); score != 0 {
+		t.Errorf("空图像的相似度为 %v, want 0", score)
+	}
+	// 单元格中心在截图之外，裁剪区域为空
+	board, _, _ := benchBoard(t)
+	b := board.Bounds()
+	if state, _ := templates.RecognizeWithConfidence(board, b.Dx()+100, b.Dy()+100, render.DefaultCellSize, render.DefaultCellSize); state != cell.Unobserved {
+		t.Errorf("state = %v, want Unobserved", state)
+	}
+}
+
+func TestTemplateClassifierUnobservedIsDoubtful(t *testing.T) {
+	templates := degrade.RenderedTemplates(render.DefaultCellSize)
+	board, horizontalLines, verticalLines := occludedBoard(t, 3, 5)
+	x := (verticalLines[5] + verticalLines[6]) / 2
+	y := (horizontalLines[3] + horizontalLines[4]) / 2
+	state, confidence := templates.RecognizeWithConfidence(board, x, y, render.DefaultCellSize, render.DefaultCellSize)
+	if state != cell.Unobserved || confidence >= identify.MinConfidence {
+		t.Errorf("被遮挡的格子识别为 %s，置信度 %.2f，应为无法观测且需要重新截图", identify.StateName(state), confidence)
+	}
+}
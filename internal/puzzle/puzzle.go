@@ -0,0 +1,128 @@
+// Package puzzle 生成具有唯一解的静态扫雷谜题
+package puzzle
+
+import (
+	"fmt"
+	"image"
+	"math/rand/v2"
+
+	"minego/internal/cell"
+	"minego/internal/solver"
+)
+
+// Puzzle 静态扫雷谜题：部分格子已翻开，其余覆盖格只有一种地雷分布与之相容
+type Puzzle struct {
+	Grid      [][]cell.GridCell // 谜题盘面，覆盖格为 cell.Unknown
+	Mines     [][]bool          // 唯一解的地雷分布
+	MineCount int               // 地雷总数，作为全局约束提供给解题者
+}
+
+// Generate 按种子随机布雷并生成谜题
+// 初始时翻开所有非雷格，随后按随机顺序逐个尝试覆盖已翻开的线索，
+// 只要解仍然唯一就保留覆盖，最终得到一个无法再删除任何线索的谜题
+func Generate(rows, cols, mineCount int, seed uint64) (*Puzzle, error) {
+	if rows <= 0 || cols <= 0 {
+		return nil, fmt.Errorf("无效的盘面尺寸 %dx%d", rows, cols)
+	}
+	if mineCount < 0 || mineCount >= rows*cols {
+		return nil, fmt.Errorf("地雷数 %d 超出范围 [0, %d)", mineCount, rows*cols)
+	}
+	rng := rand.New(rand.NewPCG(seed, seed^0x9e3779b97f4a7c15))
+
+	p := &Puzzle{
+		Grid:      make([][]cell.GridCell, rows),
+		Mines:     make([][]bool, rows),
+		MineCount: mineCount,
+	}
+	for i := range rows {
+		p.Grid[i] = make([]cell.GridCell, cols)
+		p.Mines[i] = make([]bool, cols)
+	}
+	for _, k := range rng.Perm(rows * cols)[:mineCount] {
+		p.Mines[k/cols][k%cols] = true
+	}
+
+	// 完全翻开的盘面：非雷格显示数字，雷格保持覆盖
+	for i := range rows {
+		for j := range cols {
+			p.Grid[i][j].Position = image.Point{X: j, Y: i}
+			p.Grid[i][j].State = p.revealedState(i, j)
+		}
+	}
+
+	p.minimize(rng)
+	return p, nil
+}
+
+// searchBudget 删除线索时单次唯一性检查的搜索节点预算
+const searchBudget = 200000
+
+// Solutions 统计与当前盘面和地雷总数相容的地雷分布数量，达到 limit 时停止
+func (p *Puzzle) Solutions(limit int) int {
+	n, equations, total := p.equations()
+	return solver.CountSolutions(n, equations, total, limit)
+}
+
+// equations 构建盘面约束方程组，返回变量数、方程组以及剩余地雷数
+func (p *Puzzle) equations() (int, []solver.Equation, int) {
+	pointID, equations := solver.NewSolver(p.Grid).BuildEquations()
+	flagged := 0
+	for _, row := range p.Grid {
+		for _, c := range row {
+			if c.State == cell.Flagged || c.State == cell.Mine {
+				flagged++
+			}
+		}
+	}
+	return pointID.Len(), equations, p.MineCount - flagged
+}
+
+// Clues 返回谜题中已翻开的线索数量
+func (p *Puzzle) Clues() int {
+	clues := 0
+	for _, row := range p.Grid {
+		for _, c := range row {
+			if c.State != cell.Unknown {
+				clues++
+			}
+		}
+	}
+	return clues
+}
+
+// minimize 贪心删除线索，保持解唯一
+// 唯一性检查超出搜索预算时保守地保留该线索，因此结果始终唯一
+func (p *Puzzle) minimize(rng *rand.Rand) {
+	rows, cols := len(p.Grid), len(p.Grid[0])
+	for _, k := range rng.Perm(rows * cols) {
+		c := &p.Grid[k/cols][k%cols]
+		if c.State == cell.Unknown {
+			continue
+		}
+		state := c.State
+		c.State = cell.Unknown
+		n, equations, total := p.equations()
+		if count, ok := solver.CountSolutionsBudget(n, equations, total, 2, searchBudget); !ok || count != 1 {
+			c.State = state
+		}
+	}
+}
+
+// revealedState 返回格子翻开后的状态
+func (p *Puzzle) revealedState(row, col int) cell.CellState {
+	if p.Mines[row][col] {
+		return cell.Unknown
+	}
+	count := 0
+	for i := row - 1; i <= row+1; i++ {
+		for j := col - 1; j <= col+1; j++ {
+			if i >= 0 && i < len(p.Mines) && j >= 0 && j < len(p.Mines[i]) && p.Mines[i][j] {
+				count++
+			}
+		}
+	}
+	if count == 0 {
+		return cell.Empty
+	}
+	return cell.Number1 + cell.CellState(count-1)
+}
@@ -0,0 +1,66 @@
+package puzzle_test
+
+import (
+	"testing"
+
+	"minego/internal/cell"
+	"minego/internal/identify"
+	"minego/internal/puzzle"
+	"minego/internal/solver"
+)
+
+func TestGenerateUnique(t *testing.T) {
+	for seed := uint64(1); seed <= 20; seed++ {
+		p, err := puzzle.Generate(6, 6, 8, seed)
+		if err != nil {
+			t.Fatalf("种子 %d: 生成谜题失败: %v", seed, err)
+		}
+		pointID, equations := solver.NewSolver(p.Grid).BuildEquations()
+		if count, ok := solver.CountSolutionsBudget(pointID.Len(), equations, p.MineCount, 2, 1000000); !ok || count != 1 {
+			t.Errorf("种子 %d: 解的数量 %d (ok=%v), want 1", seed, count, ok)
+		}
+		checkClues(t, seed, p)
+	}
+}
+
+// checkClues 检查地雷总数以及每条线索都与地雷分布一致
+func checkClues(t *testing.T, seed uint64, p *puzzle.Puzzle) {
+	t.Helper()
+	mines := 0
+	for i, row := range p.Grid {
+		for j, c := range row {
+			if p.Mines[i][j] {
+				mines++
+			}
+			if c.State == cell.Unknown {
+				continue
+			}
+			if p.Mines[i][j] {
+				t.Errorf("种子 %d: 地雷 (%d,%d) 被翻开为 %s", seed, i, j, identify.StateName(c.State))
+				continue
+			}
+			want := cell.Empty
+			if n := neighborMines(p.Mines, i, j); n > 0 {
+				want = cell.Number1 + cell.CellState(n-1)
+			}
+			if c.State != want {
+				t.Errorf("种子 %d: 线索 (%d,%d) 为 %s, want %s", seed, i, j, identify.StateName(c.State), identify.StateName(want))
+			}
+		}
+	}
+	if mines != p.MineCount {
+		t.Errorf("种子 %d: 布雷 %d 个, want %d", seed, mines, p.MineCount)
+	}
+}
+
+func neighborMines(mines [][]bool, row, col int) int {
+	count := 0
+	for i := max(row-1, 0); i <= min(row+1, len(mines)-1); i++ {
+		for j := max(col-1, 0); j <= min(col+1, len(mines[i])-1); j++ {
+			if mines[i][j] {
+				count++
+			}
+		}
+	}
+	return count
+}
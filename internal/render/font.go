@@ -0,0 +1,74 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// 5x7 点阵字体，每行 5 个字符，'1' 表示有像素
+var glyphs = map[rune][7]string{
+	'0': {"01110", "10001", "10011", "10101", "11001", "10001", "01110"},
+	'1': {"00100", "01100", "00100", "00100", "00100", "00100", "01110"},
+	'2': {"01110", "10001", "00001", "00010", "00100", "01000", "11111"},
+	'3': {"11110", "00001", "00001", "01110", "00001", "00001", "11110"},
+	'4': {"00010", "00110", "01010", "10010", "11111", "00010", "00010"},
+	'5': {"11111", "10000", "11110", "00001", "00001", "10001", "01110"},
+	'6': {"00110", "01000", "10000", "11110", "10001", "10001", "01110"},
+	'7': {"11111", "00001", "00010", "00100", "01000", "01000", "01000"},
+	'8': {"01110", "10001", "10001", "01110", "10001", "10001", "01110"},
+	'9': {"01110", "10001", "10001", "01111", "00001", "00010", "01100"},
+	'?': {"01110", "10001", "00001", "00010", "00100", "00000", "00100"},
+	'-': {"00000", "00000", "00000", "11111", "00000", "00000", "00000"},
+	'.': {"00000", "00000", "00000", "00000", "00000", "01100", "01100"},
+	'%': {"11001", "11010", "00010", "00100", "01000", "01011", "10011"},
+	'E': {"11111", "10000", "10000", "11110", "10000", "10000", "11111"},
+	'F': {"11111", "10000", "10000", "11110", "10000", "10000", "10000"},
+	'M': {"10001", "11011", "10101", "10101", "10001", "10001", "10001"},
+	'Q': {"01110", "10001", "10001", "10001", "10101", "10010", "01101"},
+	'U': {"10001", "10001", "10001", "10001", "10001", "10001", "01110"},
+	'W': {"10001", "10001", "10001", "10101", "10101", "11011", "10001"},
+	'X': {"10001", "10001", "01010", "00100", "01010", "10001", "10001"},
+	'L': {"10000", "10000", "10000", "10000", "10000", "10000", "11111"},
+}
+
+const (
+	glyphWidth  = 5
+	glyphHeight = 7
+)
+
+// TextSize 返回文本按指定缩放绘制后的尺寸
+func TextSize(text string, scale int) image.Point {
+	n := len([]rune(text))
+	if n == 0 {
+		return image.Point{}
+	}
+	return image.Point{
+		X: (n*(glyphWidth+1) - 1) * scale,
+		Y: glyphHeight * scale,
+	}
+}
+
+// DrawText 以 (x, y) 为左上角绘制点阵文本，未定义的字符绘制为空白
+func DrawText(img draw.Image, x, y int, text string, scale int, c color.Color) {
+	for _, r := range text {
+		if g, ok := glyphs[r]; ok {
+			for gy, row := range g {
+				for gx, bit := range row {
+					if bit != '1' {
+						continue
+					}
+					rect := image.Rect(x+gx*scale, y+gy*scale, x+(gx+1)*scale, y+(gy+1)*scale)
+					draw.Draw(img, rect, image.NewUniform(c), image.Point{}, draw.Src)
+				}
+			}
+		}
+		x += (glyphWidth + 1) * scale
+	}
+}
+
+// DrawTextCentered 以 center 为中心绘制点阵文本
+func DrawTextCentered(img draw.Image, center image.Point, text string, scale int, c color.Color) {
+	size := TextSize(text, scale)
+	DrawText(img, center.X-size.X/2, center.Y-size.Y/2, text, scale, c)
+}
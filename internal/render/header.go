@@ -0,0 +1,120 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+
+	"minego/internal/cell"
+	"minego/internal/header"
+)
+
+var (
+	LEDBackground = color.RGBA{0, 0, 0, 255}
+	LEDLitColor   = color.RGBA{255, 0, 0, 255}
+	LEDDimColor   = color.RGBA{96, 0, 0, 255} // 未点亮的段位
+	ButtonColor   = color.RGBA{192, 192, 192, 255}
+	FaceColor     = color.RGBA{255, 255, 0, 255}
+	FeatureColor  = color.RGBA{0, 0, 0, 255} // 笑脸轮廓与五官
+)
+
+// HeaderHeight 返回信息栏高度
+func HeaderHeight(cellSize int) int {
+	return cellSize*25/16 + 2*max(cellSize/3, 2)
+}
+
+// Window 渲染带信息栏的经典布局：上方左侧为剩余雷数、中间为笑脸按钮、右侧为计时器，下方为雷区。
+// 返回整幅图像以及雷区在其中的范围
+func Window(grid [][]cell.GridCell, cellSize, mines, seconds int, face header.FaceState) (*image.RGBA, image.Rectangle) {
+	board := Board(grid, cellSize)
+	headerHeight := HeaderHeight(cellSize)
+	img := image.NewRGBA(image.Rect(0, 0, board.Bounds().Dx(), board.Bounds().Dy()+headerHeight))
+	fill(img, img.Bounds(), MarginColor)
+	boardRect := board.Bounds().Add(image.Point{Y: headerHeight})
+	draw.Draw(img, boardRect, board, image.Point{}, draw.Src)
+
+	pad := max(cellSize/3, 2)
+	panelH := cellSize * 25 / 16 // 与 XP 一致：16 像素格子对应 41x25 的数码管面板
+	panelW := panelH * 41 / 25
+	DrawCounter(img, image.Rect(Margin+pad, pad, Margin+pad+panelW, pad+panelH), mines)
+	right := img.Bounds().Dx() - Margin - pad
+	DrawCounter(img, image.Rect(right-panelW, pad, right, pad+panelH), seconds)
+	center := img.Bounds().Dx() / 2
+	DrawFace(img, image.Rect(center-panelH/2, pad, center-panelH/2+panelH, pad+panelH), face)
+	return img, boardRect
+}
+
+// DrawCounter 在指定面板中绘制三位七段数码管数值
+func DrawCounter(img *image.RGBA, panel image.Rectangle, value int) {
+	fill(img, panel, LEDBackground)
+	masks := header.Encode(value)
+	for k, digit := range header.DigitRects(panel) {
+		for s, seg := range header.SegmentRects(digit.Dx(), digit.Dy()) {
+			c := LEDDimColor
+			if masks[k]&(1<<s) != 0 {
+				c = LEDLitColor
+			}
+			fill(img, seg.Add(digit.Min), c)
+		}
+	}
+}
+
+// DrawFace 在指定按钮区域绘制笑脸：灰色按钮、黑色轮廓的黄色圆脸以及对应状态的五官
+func DrawFace(img *image.RGBA, button image.Rectangle, state header.FaceState) {
+	fill(img, button, ButtonColor)
+	c := image.Pt((button.Min.X+button.Max.X)/2, (button.Min.Y+button.Max.Y)/2)
+	r := float64(button.Dx()) * 0.35
+	disc(img, c, r, FeatureColor)
+	disc(img, c, r-max(r/8, 1), FaceColor)
+
+	at := func(u, v float64) image.Point {
+		return image.Pt(c.X+int(u*r+0.5), c.Y+int(v*r+0.5))
+	}
+	stroke := max(r/8, 1)
+	switch state {
+	case header.FaceWon:
+		p, q := at(-0.6, -0.4), at(0.6, -0.15)
+		fill(img, image.Rect(p.X, p.Y, q.X, q.Y), FeatureColor)
+	case header.FaceLost:
+		for _, eye := range [2]float64{-0.35, 0.35} {
+			for t := -0.2; t <= 0.2; t += 0.05 {
+				disc(img, at(eye+t, -0.3+t), stroke/2, FeatureColor)
+				disc(img, at(eye+t, -0.3-t), stroke/2, FeatureColor)
+			}
+		}
+	default:
+		for _, eye := range [2]float64{-0.35, 0.35} {
+			p, q := at(eye-0.1, -0.4), at(eye+0.1, -0.2)
+			fill(img, image.Rect(p.X, p.Y, q.X, q.Y), FeatureColor)
+		}
+	}
+
+	switch state {
+	case header.FacePressing:
+		disc(img, at(0, 0.45), 0.2*r, FeatureColor)
+	case header.FaceLost:
+		arc(img, at, 0.3, 0.55, stroke)
+	default:
+		arc(img, at, 0.55, 0.3, stroke)
+	}
+}
+
+// arc 沿抛物线绘制嘴巴，mid 与 corner 为嘴中与嘴角相对脸部半径的纵向位置
+func arc(img *image.RGBA, at func(u, v float64) image.Point, mid, corner, stroke float64) {
+	for u := -0.5; u <= 0.5; u += 0.02 {
+		v := corner + (mid-corner)*(1-(u/0.5)*(u/0.5))
+		disc(img, at(u, v), stroke/2, FeatureColor)
+	}
+}
+
+// disc 绘制以 c 为圆心、半径 r 的实心圆，半径不足一像素时只画一个点
+func disc(img *image.RGBA, c image.Point, r float64, col color.Color) {
+	n := int(r + 0.5)
+	for dy := -n; dy <= n; dy++ {
+		for dx := -n; dx <= n; dx++ {
+			if float64(dx*dx+dy*dy) <= r*r+0.25 {
+				img.Set(c.X+dx, c.Y+dy, col)
+			}
+		}
+	}
+}
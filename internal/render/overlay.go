@@ -0,0 +1,155 @@
+package render
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+
+	"minego/internal/cell"
+	"minego/internal/identify"
+	"minego/internal/solver"
+)
+
+// OverlayCellSize 标注图中单元格的最小边长（像素），截图中的格子更小时按整数倍放大
+const OverlayCellSize = 48
+
+var (
+	OverlayLineColor   = color.RGBA{0, 255, 255, 255}   // 检测到的网格线
+	OverlayLabelColor  = color.RGBA{0, 0, 0, 200}       // 文字底色
+	OverlayTextColor   = color.RGBA{255, 255, 255, 255} // 状态与点击序号
+	OverlayDoubtColor  = color.RGBA{255, 90, 90, 255}   // 低于 identify.MinConfidence 的置信度
+	OverlaySafeColor   = color.RGBA{0, 200, 0, 255}
+	OverlayMineColor   = color.RGBA{230, 0, 0, 255}
+	OverlayGuessColor  = color.RGBA{255, 200, 0, 255}
+	OverlayUnflagColor = color.RGBA{200, 0, 200, 255}
+)
+
+// Decision 一轮迭代中求解器的决策，坐标 X 为列、Y 为行
+type Decision struct {
+	Safe   []image.Point // 左键翻开的格子（含猜测）
+	Mines  []image.Point // 右键插旗的格子
+	Unflag []image.Point // 右键取消的插错旗帜
+	Guess  *solver.Guess // 没有确定解时的猜测，可为 nil
+	Clicks []image.Point // 实际点击的顺序
+}
+
+// Overlay 在雷区截图上绘制本轮的识别与决策结果：检测到的网格线，每格的识别状态与置信度，
+// 安全格、地雷、取消的旗帜与猜测（含地雷概率）的边框，以及点击序号。
+// 网格线坐标相对截图左上角，与 identify 的约定相同
+func Overlay(img image.Image, horizontalLines, verticalLines []int, cells [][]cell.GridCell, d Decision) *image.RGBA {
+	cellSize := 0
+	for i := range len(horizontalLines) - 1 {
+		if size := horizontalLines[i+1] - horizontalLines[i]; cellSize == 0 || size < cellSize {
+			cellSize = size
+		}
+	}
+	for j := range len(verticalLines) - 1 {
+		if size := verticalLines[j+1] - verticalLines[j]; cellSize == 0 || size < cellSize {
+			cellSize = size
+		}
+	}
+	k := 1
+	if cellSize > 0 {
+		k = max((OverlayCellSize+cellSize-1)/cellSize, 1)
+	}
+	out := enlarge(img, k)
+	bounds := out.Bounds()
+
+	for _, y := range horizontalLines {
+		fill(out, image.Rect(0, y*k, bounds.Dx(), y*k+1), OverlayLineColor)
+	}
+	for _, x := range verticalLines {
+		fill(out, image.Rect(x*k, 0, x*k+1, bounds.Dy()), OverlayLineColor)
+	}
+
+	// cellRect 返回放大后格子内部（不含网格线）的矩形
+	cellRect := func(p image.Point) (image.Rectangle, bool) {
+		if p.Y < 0 || p.Y+1 >= len(horizontalLines) || p.X < 0 || p.X+1 >= len(verticalLines) {
+			return image.Rectangle{}, false
+		}
+		return image.Rect(verticalLines[p.X]*k+1, horizontalLines[p.Y]*k+1, verticalLines[p.X+1]*k, horizontalLines[p.Y+1]*k), true
+	}
+
+	for _, row := range cells {
+		for _, c := range row {
+			rect, ok := cellRect(c.Position)
+			if !ok {
+				continue
+			}
+			label(out, rect.Min, identify.StateName(c.State), OverlayTextColor)
+			confidence := fmt.Sprintf("%d%%", int(c.Confidence*100+0.5))
+			textColor := OverlayTextColor
+			if c.Confidence < identify.MinConfidence {
+				textColor = OverlayDoubtColor
+			}
+			label(out, image.Pt(rect.Min.X, rect.Max.Y-glyphHeight-2), confidence, textColor)
+		}
+	}
+
+	for _, group := range []struct {
+		points []image.Point
+		color  color.RGBA
+	}{{d.Safe, OverlaySafeColor}, {d.Mines, OverlayMineColor}, {d.Unflag, OverlayUnflagColor}} {
+		for _, p := range group.points {
+			if rect, ok := cellRect(p); ok {
+				frame(out, rect, 2, group.color)
+			}
+		}
+	}
+	if d.Guess != nil {
+		if rect, ok := cellRect(d.Guess.Point); ok {
+			frame(out, rect, 3, OverlayGuessColor)
+			probability := fmt.Sprintf("%d%%", int(d.Guess.Probability*100+0.5))
+			size := TextSize(probability, 1)
+			center := image.Pt((rect.Min.X+rect.Max.X)/2, (rect.Min.Y+rect.Max.Y)/2)
+			label(out, center.Sub(size.Div(2)), probability, OverlayGuessColor)
+		}
+	}
+
+	for n, p := range d.Clicks {
+		rect, ok := cellRect(p)
+		if !ok {
+			continue
+		}
+		text := fmt.Sprint(n + 1)
+		label(out, image.Pt(rect.Max.X-TextSize(text, 1).X-2, rect.Min.Y), text, OverlayTextColor)
+	}
+	return out
+}
+
+// enlarge 按整数倍最近邻放大，结果以 (0,0) 为原点
+func enlarge(img image.Image, k int) *image.RGBA {
+	b := img.Bounds()
+	out := image.NewRGBA(image.Rect(0, 0, b.Dx()*k, b.Dy()*k))
+	for y := range b.Dy() {
+		row := out.Pix[y*k*out.Stride:]
+		for x := range b.Dx() {
+			c := color.RGBAModel.Convert(img.At(b.Min.X+x, b.Min.Y+y)).(color.RGBA)
+			for dx := range k {
+				copy(row[4*(x*k+dx):], []uint8{c.R, c.G, c.B, c.A})
+			}
+		}
+		// 同一行像素纵向重复 k 次
+		for dy := 1; dy < k; dy++ {
+			copy(out.Pix[(y*k+dy)*out.Stride:][:out.Stride], row[:out.Stride])
+		}
+	}
+	return out
+}
+
+// label 以 p 为左上角绘制带半透明底色的文本
+func label(img *image.RGBA, p image.Point, text string, c color.Color) {
+	size := TextSize(text, 1)
+	box := image.Rectangle{Min: p, Max: p.Add(size).Add(image.Pt(2, 2))}
+	draw.Draw(img, box, image.NewUniform(OverlayLabelColor), image.Point{}, draw.Over)
+	DrawText(img, p.X+1, p.Y+1, text, 1, c)
+}
+
+// frame 沿矩形内侧绘制宽 width 的边框
+func frame(img *image.RGBA, rect image.Rectangle, width int, c color.Color) {
+	fill(img, image.Rect(rect.Min.X, rect.Min.Y, rect.Max.X, rect.Min.Y+width), c)
+	fill(img, image.Rect(rect.Min.X, rect.Max.Y-width, rect.Max.X, rect.Max.Y), c)
+	fill(img, image.Rect(rect.Min.X, rect.Min.Y, rect.Min.X+width, rect.Max.Y), c)
+	fill(img, image.Rect(rect.Max.X-width, rect.Min.Y, rect.Max.X, rect.Max.Y), c)
+}
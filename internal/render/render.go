@@ -0,0 +1,155 @@
+// Package render 将网格状态渲染为 Win7 风格的雷区图像，
+// 用于生成谜题图片以及在没有真实窗口时驱动识别流程
+package render
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+
+	"minego/internal/cell"
+	"minego/internal/identify"
+)
+
+const (
+	DefaultCellSize = 36 // 默认单元格边长（像素）
+	Margin          = 3  // 雷区外边距，对应截图时的边界扩展
+)
+
+var (
+	LineColor       = color.RGBA{7, 8, 9, 255}
+	MarginColor     = color.RGBA{230, 234, 240, 255}
+	CoveredColor    = color.RGBA{60, 110, 200, 255}
+	RevealedColor   = color.RGBA{200, 210, 225, 255}
+	FlagColor       = color.RGBA{220, 30, 30, 255}
+	FlagPoleColor   = color.RGBA{40, 40, 40, 255}
+	Number7Color    = color.RGBA{160, 0, 160, 255}
+	Number8Color    = color.RGBA{128, 128, 128, 255}
+	numberColorsMap = map[cell.CellState]color.RGBA{
+		cell.Number1: identify.Number1FeatureColor,
+		cell.Number2: identify.Number2FeatureColor,
+		cell.Number3: identify.Number3Color,
+		cell.Number4: identify.Number4Color,
+		cell.Number5: identify.Number5Color,
+		cell.Number6: identify.Number6Color,
+		cell.Number7: Number7Color,
+		cell.Number8: Number8Color,
+	}
+)
+
+// Lines 返回渲染图像中水平线与垂直线的坐标，可直接传给 identify.IdentifyMinesweeper
+func Lines(rows, cols, cellSize int) ([]int, []int) {
+	horizontalLines := make([]int, rows+1)
+	for i := range horizontalLines {
+		horizontalLines[i] = Margin + i*cellSize
+	}
+	verticalLines := make([]int, cols+1)
+	for j := range verticalLines {
+		verticalLines[j] = Margin + j*cellSize
+	}
+	return horizontalLines, verticalLines
+}
+
+// Board 将网格状态渲染为图像
+func Board(grid [][]cell.GridCell, cellSize int) *image.RGBA {
+	rows := len(grid)
+	cols := 0
+	if rows > 0 {
+		cols = len(grid[0])
+	}
+	img := image.NewRGBA(image.Rect(0, 0, cols*cellSize+2*Margin+1, rows*cellSize+2*Margin+1))
+	draw.Draw(img, img.Bounds(), image.NewUniform(MarginColor), image.Point{}, draw.Src)
+
+	// 网格线
+	horizontalLines, verticalLines := Lines(rows, cols, cellSize)
+	for _, y := range horizontalLines {
+		fill(img, image.Rect(Margin, y, Margin+cols*cellSize+1, y+1), LineColor)
+	}
+	for _, x := range verticalLines {
+		fill(img, image.Rect(x, Margin, x+1, Margin+rows*cellSize+1), LineColor)
+	}
+
+	for i, row := range grid {
+		for j, c := range row {
+			rect := image.Rect(verticalLines[j]+1, horizontalLines[i]+1, verticalLines[j+1], horizontalLines[i+1])
+			drawCell(img, rect, c.State, cellSize)
+		}
+	}
+	return img
+}
+
+// drawCell 绘制单个单元格内部
+func drawCell(img *image.RGBA, rect image.Rectangle, state cell.CellState, cellSize int) {
+	center := image.Point{
+		X: rect.Min.X - 1 + cellSize/2,
+		Y: rect.Min.Y - 1 + cellSize/2,
+	}
+	scale := max(cellSize/12, 1)
+
+	switch {
+	case state >= cell.Number1 && state <= cell.Number8:
+		fill(img, rect, RevealedColor)
+		DrawTextCentered(img, center, string(rune('0'+int(state))), scale, numberColorsMap[state])
+	case state == cell.Empty:
+		fill(img, rect, RevealedColor)
+	case state == cell.Flagged:
+		fill(img, rect, CoveredColor)
+		drawFlag(img, center, scale)
+	case state == cell.QuestionMark:
+		fill(img, rect, CoveredColor)
+		DrawTextCentered(img, center, "?", scale, identify.QuestionMarkColor)
+	case state == cell.Unobserved:
+		// 无法观测的格子画成遮挡物的样子
+		fill(img, rect, MarginColor)
+		DrawTextCentered(img, center, "-", scale, LineColor)
+	case state == cell.Mine:
+		fill(img, rect, RevealedColor)
+		drawMine(img, center, scale)
+	case state == cell.Exploded:
+		fill(img, rect, identify.ExplodedColor)
+		drawMine(img, center, scale)
+	case state == cell.Misflag:
+		fill(img, rect, RevealedColor)
+		drawMine(img, center, scale)
+		drawCross(img, center, scale)
+	default:
+		fill(img, rect, CoveredColor)
+	}
+}
+
+// drawFlag 绘制旗帜：旗杆、旗面、底座以及识别用的高亮点
+func drawFlag(img *image.RGBA, center image.Point, scale int) {
+	poleX := center.X + scale
+	fill(img, image.Rect(poleX, center.Y-4*scale, poleX+scale, center.Y+4*scale), FlagPoleColor)
+	fill(img, image.Rect(center.X-3*scale, center.Y+3*scale, center.X+4*scale, center.Y+4*scale), FlagPoleColor)
+	fill(img, image.Rect(poleX-4*scale, center.Y-4*scale, poleX, center.Y-scale), FlagColor)
+	fill(img, image.Rect(center.X-scale, center.Y, center.X+scale/2+1, center.Y+scale+1), identify.FlaggedColor)
+}
+
+// drawMine 绘制地雷：实心圆与左上角高光
+func drawMine(img *image.RGBA, center image.Point, scale int) {
+	r := 4 * scale
+	for dy := -r; dy <= r; dy++ {
+		for dx := -r; dx <= r; dx++ {
+			if dx*dx+dy*dy <= r*r {
+				img.Set(center.X+dx, center.Y+dy, identify.MineColor)
+			}
+		}
+	}
+	fill(img, image.Rect(center.X-2*scale, center.Y-2*scale, center.X-scale, center.Y-scale), MarginColor)
+}
+
+// drawCross 在地雷上绘制表示插错旗帜的叉
+func drawCross(img *image.RGBA, center image.Point, scale int) {
+	r := 5 * scale
+	for d := -r; d <= r; d++ {
+		for w := 0; w < scale; w++ {
+			img.Set(center.X+d+w, center.Y+d, identify.MisflagColor)
+			img.Set(center.X+d+w, center.Y-d, identify.MisflagColor)
+		}
+	}
+}
+
+func fill(img *image.RGBA, rect image.Rectangle, c color.Color) {
+	draw.Draw(img, rect, image.NewUniform(c), image.Point{}, draw.Src)
+}
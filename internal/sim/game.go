@@ -0,0 +1,291 @@
+// Package sim 在内存中模拟扫雷对局，用于离线评估求解策略
+package sim
+
+import (
+	"image"
+	"math/rand/v2"
+
+	"minego/internal/cell"
+	"minego/internal/solver"
+)
+
+// Game 一局模拟扫雷
+type Game struct {
+	rows, cols int
+	mineCount  int
+	mines      [][]bool
+	revealed   [][]bool
+	flagged    [][]bool
+	covered    int // 尚未翻开的非雷格数量
+	lost       bool
+}
+
+// NewGame 按种子布雷，首次点击位置及其周围保证无雷（与 Win7 扫雷一致）
+func NewGame(rows, cols, mineCount int, seed uint64, first image.Point) *Game {
+	g := &Game{
+		rows:      rows,
+		cols:      cols,
+		mineCount: mineCount,
+		mines:     makeBoolGrid(rows, cols),
+		revealed:  makeBoolGrid(rows, cols),
+		flagged:   makeBoolGrid(rows, cols),
+	}
+
+	candidates := make([]int, 0, rows*cols)
+	for k := range rows * cols {
+		if abs(k/cols-first.Y) > 1 || abs(k%cols-first.X) > 1 {
+			candidates = append(candidates, k)
+		}
+	}
+	rng := rand.New(rand.NewPCG(seed, seed^0x5eed))
+	rng.Shuffle(len(candidates), func(a, b int) { candidates[a], candidates[b] = candidates[b], candidates[a] })
+	g.mineCount = min(mineCount, len(candidates))
+	for _, k := range candidates[:g.mineCount] {
+		g.mines[k/cols][k%cols] = true
+	}
+	g.covered = rows*cols - g.mineCount
+	return g
+}
+
+// FirstClick 返回种子对应的首次点击位置，同一种子在所有策略间保持一致
+func FirstClick(rows, cols int, seed uint64) image.Point {
+	rng := rand.New(rand.NewPCG(seed, seed^0xf1257))
+	return image.Point{X: rng.IntN(cols), Y: rng.IntN(rows)}
+}
+
+// Reveal 翻开格子，数字为 0 时自动展开，踩雷返回 true
+func (g *Game) Reveal(p image.Point) bool {
+	if !g.inside(p) || g.revealed[p.Y][p.X] || g.flagged[p.Y][p.X] {
+		return false
+	}
+	if g.mines[p.Y][p.X] {
+		g.lost = true
+		return true
+	}
+	stack := []image.Point{p}
+	for len(stack) > 0 {
+		q := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if g.revealed[q.Y][q.X] {
+			continue
+		}
+		g.revealed[q.Y][q.X] = true
+		g.flagged[q.Y][q.X] = false
+		g.covered--
+		if g.count(q) != 0 {
+			continue
+		}
+		for _, nb := range g.neighbors(q) {
+			if !g.revealed[nb.Y][nb.X] && !g.mines[nb.Y][nb.X] {
+				stack = append(stack, nb)
+			}
+		}
+	}
+	return false
+}
+
+// Flag 标记地雷
+func (g *Game) Flag(p image.Point) {
+	if g.inside(p) && !g.revealed[p.Y][p.X] {
+		g.flagged[p.Y][p.X] = true
+	}
+}
+
+// Unflag 取消旗帜
+func (g *Game) Unflag(p image.Point) {
+	if g.inside(p) {
+		g.flagged[p.Y][p.X] = false
+	}
+}
+
+// IsMine 返回格子是否有雷
+func (g *Game) IsMine(p image.Point) bool {
+	return g.inside(p) && g.mines[p.Y][p.X]
+}
+
+// Won 所有非雷格都已翻开
+func (g *Game) Won() bool {
+	return !g.lost && g.covered == 0
+}
+
+// Lost 是否踩雷
+func (g *Game) Lost() bool {
+	return g.lost
+}
+
+// MineCount 返回地雷总数
+func (g *Game) MineCount() int {
+	return g.mineCount
+}
+
+// Grid 返回玩家可见的盘面，格式与 identify 的识别结果一致
+func (g *Game) Grid() [][]cell.GridCell {
+	grid := make([][]cell.GridCell, g.rows)
+	for i := range grid {
+		grid[i] = make([]cell.GridCell, g.cols)
+		for j := range grid[i] {
+			p := image.Point{X: j, Y: i}
+			grid[i][j].Position = p
+			switch {
+			case g.revealed[i][j]:
+				grid[i][j].State = cell.Empty + cell.CellState(g.count(p))
+			case g.flagged[i][j]:
+				grid[i][j].State = cell.Flagged
+			default:
+				grid[i][j].State = cell.Unknown
+			}
+		}
+	}
+	return grid
+}
+
+// Result 一局模拟的结果
+type Result struct {
+	Won     bool
+	Moves   int            // 求解轮数
+	Unflags int            // 取消插错旗帜的次数
+	Guesses []GuessOutcome // 所有猜测及其结果
+}
+
+// GuessOutcome 一次猜测的预测概率与实际结果
+type GuessOutcome struct {
+	Point     image.Point
+	Predicted float64
+	Mine      bool
+	Random    bool // 求解器没有给出操作时的随机猜测，预测概率取剩余地雷密度
+}
+
+// Play 用指定求解器配置下完一局。首次点击后在 wrongFlags 个随机的非雷格上插旗，
+// 模拟用户误插或误识别的旗帜，求解器需要先发现并取消它们
+func Play(config solver.Config, rows, cols, mineCount, wrongFlags int, seed uint64) Result {
+	first := FirstClick(rows, cols, seed)
+	g := NewGame(rows, cols, mineCount, seed, first)
+	rng := rand.New(rand.NewPCG(seed, seed^0x6e55))
+	result := Result{}
+
+	g.Reveal(first)
+	g.misflag(wrongFlags, rand.New(rand.NewPCG(seed, seed^0xf1a9)))
+	for !g.Won() && !g.Lost() && result.Moves < rows*cols*2 {
+		result.Moves++
+		grid := g.Grid()
+		s := solver.NewSolverWithConfig(grid, config)
+		safePoints, minePoints := s.Solve()
+		guess, guessed := s.LastGuess()
+
+		// 与实时流程一致：先取消与数字矛盾的旗帜，它们可能同时是本轮的安全点
+		progress := false
+		for _, p := range s.WrongFlags() {
+			if grid[p.Y][p.X].State == cell.Flagged {
+				g.Unflag(p)
+				grid[p.Y][p.X].State = cell.Unknown
+				result.Unflags++
+				progress = true
+			}
+		}
+		for _, p := range minePoints {
+			if grid[p.Y][p.X].State == cell.Unknown {
+				g.Flag(p)
+				progress = true
+			}
+		}
+		for _, p := range safePoints {
+			if grid[p.Y][p.X].State != cell.Unknown {
+				continue
+			}
+			progress = true
+			if guessed && p == guess.Point {
+				result.Guesses = append(result.Guesses, GuessOutcome{Point: p, Predicted: guess.Probability, Mine: g.IsMine(p)})
+			}
+			if g.Reveal(p) {
+				break
+			}
+		}
+
+		// 求解器没有给出任何新操作时随机翻开一个未知格
+		if !progress && !g.Lost() {
+			unknown := make([]image.Point, 0)
+			flagged := 0
+			for _, row := range grid {
+				for _, c := range row {
+					switch c.State {
+					case cell.Unknown:
+						unknown = append(unknown, c.Position)
+					case cell.Flagged:
+						flagged++
+					}
+				}
+			}
+			if len(unknown) == 0 {
+				break
+			}
+			p := unknown[rng.IntN(len(unknown))]
+			result.Guesses = append(result.Guesses, GuessOutcome{
+				Point:     p,
+				Predicted: min(max(float64(g.mineCount-flagged)/float64(len(unknown)), 0), 1),
+				Mine:      g.IsMine(p),
+				Random:    true,
+			})
+			g.Reveal(p)
+		}
+	}
+	result.Won = g.Won()
+	return result
+}
+
+// misflag 在 n 个随机的未翻开非雷格上插旗
+func (g *Game) misflag(n int, rng *rand.Rand) {
+	candidates := make([]image.Point, 0)
+	for i := range g.rows {
+		for j := range g.cols {
+			if !g.revealed[i][j] && !g.mines[i][j] {
+				candidates = append(candidates, image.Point{X: j, Y: i})
+			}
+		}
+	}
+	rng.Shuffle(len(candidates), func(a, b int) { candidates[a], candidates[b] = candidates[b], candidates[a] })
+	for _, p := range candidates[:min(n, len(candidates))] {
+		g.Flag(p)
+	}
+}
+
+func (g *Game) count(p image.Point) int {
+	count := 0
+	for _, nb := range g.neighbors(p) {
+		if g.mines[nb.Y][nb.X] {
+			count++
+		}
+	}
+	return count
+}
+
+func (g *Game) neighbors(p image.Point) []image.Point {
+	neighbors := make([]image.Point, 0, 8)
+	for dy := -1; dy <= 1; dy++ {
+		for dx := -1; dx <= 1; dx++ {
+			q := image.Point{X: p.X + dx, Y: p.Y + dy}
+			if (dx != 0 || dy != 0) && g.inside(q) {
+				neighbors = append(neighbors, q)
+			}
+		}
+	}
+	return neighbors
+}
+
+func (g *Game) inside(p image.Point) bool {
+	return p.X >= 0 && p.X < g.cols && p.Y >= 0 && p.Y < g.rows
+}
+
+func makeBoolGrid(rows, cols int) [][]bool {
+	grid := make([][]bool, rows)
+	for i := range grid {
+		grid[i] = make([]bool, cols)
+	}
+	return grid
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,27 @@
+package sim_test
+
+import (
+	"testing"
+
+	"minego/internal/sim"
+	"minego/internal/solver"
+)
+
+func TestPlayRemovesWrongFlags(t *testing.T) {
+	unflags, wins := 0, 0
+	for seed := uint64(1); seed <= 50; seed++ {
+		result := sim.Play(solver.DefaultConfig, 9, 9, 10, 3, seed)
+		unflags += result.Unflags
+		if result.Won {
+			wins++
+		}
+	}
+	if unflags == 0 {
+		t.Errorf("50 局中插错的旗帜一面都没有取消")
+	}
+	// 插错的旗帜挡住的是非雷格，取消后还需要翻开才能获胜
+	if wins == 0 {
+		t.Errorf("插错旗帜后 50 局全负，说明取消后的格子没有被翻开")
+	}
+	t.Logf("取消旗帜 %d 次, 胜 %d 局", unflags, wins)
+}
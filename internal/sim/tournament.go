@@ -0,0 +1,169 @@
+package sim
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"runtime"
+	"sync"
+
+	"minego/internal/solver"
+)
+
+// Tournament 在完全相同的种子盘面和首次点击上对比多个求解器配置
+type Tournament struct {
+	Configs         []solver.Config
+	Rows, Cols      int
+	Mines           int
+	WrongFlags      int // 每局开局后插在非雷格上的旗帜数，检验取消旗帜的流程
+	Games           int
+	Seed            uint64 // 第 k 局使用种子 Seed+k
+	MaxExampleSeeds int    // 每个方向最多记录的示例种子数
+	Workers         int    // 并发对局数，<= 0 时使用 CPU 核数
+
+	// OnGuess 每局结束后对其中每次猜测调用一次，可为 nil，可能被并发调用
+	OnGuess func(config solver.Config, seed uint64, guess GuessOutcome)
+}
+
+// TournamentResult 锦标赛结果，Wins[c][k] 表示配置 c 是否赢下第 k 局
+type TournamentResult struct {
+	Tournament
+	Wins [][]bool
+}
+
+// PairStats 两个配置的配对比较
+type PairStats struct {
+	A, B         string
+	BothWon      int
+	BothLost     int
+	OnlyA        int      // A 赢 B 输
+	OnlyB        int      // B 赢 A 输
+	PValue       float64  // McNemar 精确检验的双侧 p 值
+	ExamplesA    []uint64 // A 赢 B 输的种子
+	ExamplesB    []uint64 // B 赢 A 输的种子
+	WinRateDelta float64  // A 胜率 - B 胜率
+}
+
+// Run 执行锦标赛
+func (t Tournament) Run() *TournamentResult {
+	result := &TournamentResult{Tournament: t, Wins: make([][]bool, len(t.Configs))}
+	for c := range t.Configs {
+		result.Wins[c] = make([]bool, t.Games)
+	}
+
+	workers := t.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for k := range jobs {
+				seed := t.Seed + uint64(k)
+				for c, config := range t.Configs {
+					res := Play(config, t.Rows, t.Cols, t.Mines, t.WrongFlags, seed)
+					result.Wins[c][k] = res.Won
+					if t.OnGuess != nil {
+						for _, guess := range res.Guesses {
+							t.OnGuess(config, seed, guess)
+						}
+					}
+				}
+			}
+		}()
+	}
+	for k := range t.Games {
+		jobs <- k
+	}
+	close(jobs)
+	wg.Wait()
+	return result
+}
+
+// WinCount 返回配置 c 的胜局数
+func (r *TournamentResult) WinCount(c int) int {
+	count := 0
+	for _, won := range r.Wins[c] {
+		if won {
+			count++
+		}
+	}
+	return count
+}
+
+// Compare 对配置 a 与 b 做配对比较
+func (r *TournamentResult) Compare(a, b int) PairStats {
+	stats := PairStats{A: r.Configs[a].Name, B: r.Configs[b].Name}
+	for k := range r.Games {
+		wa, wb := r.Wins[a][k], r.Wins[b][k]
+		seed := r.Seed + uint64(k)
+		switch {
+		case wa && wb:
+			stats.BothWon++
+		case !wa && !wb:
+			stats.BothLost++
+		case wa:
+			stats.OnlyA++
+			if len(stats.ExamplesA) < r.MaxExampleSeeds {
+				stats.ExamplesA = append(stats.ExamplesA, seed)
+			}
+		default:
+			stats.OnlyB++
+			if len(stats.ExamplesB) < r.MaxExampleSeeds {
+				stats.ExamplesB = append(stats.ExamplesB, seed)
+			}
+		}
+	}
+	if r.Games > 0 {
+		stats.WinRateDelta = float64(stats.OnlyA-stats.OnlyB) / float64(r.Games)
+	}
+	stats.PValue = mcNemarExact(stats.OnlyA, stats.OnlyB)
+	return stats
+}
+
+// WriteReport 输出各配置胜率以及两两配对比较
+func (r *TournamentResult) WriteReport(w io.Writer) {
+	fmt.Fprintf(w, "盘面 %dx%d, 地雷 %d, 共 %d 局 (种子 %d..%d)\n",
+		r.Rows, r.Cols, r.Mines, r.Games, r.Seed, r.Seed+uint64(max(r.Games-1, 0)))
+	if r.WrongFlags > 0 {
+		fmt.Fprintf(w, "每局开局后在非雷格上插 %d 面旗帜\n", r.WrongFlags)
+	}
+	for c, config := range r.Configs {
+		wins := r.WinCount(c)
+		fmt.Fprintf(w, "  %-10s 胜 %d / 负 %d, 胜率 %.2f%%\n", config.Name, wins, r.Games-wins, 100*float64(wins)/float64(max(r.Games, 1)))
+	}
+	for a := range r.Configs {
+		for b := a + 1; b < len(r.Configs); b++ {
+			s := r.Compare(a, b)
+			fmt.Fprintf(w, "\n%s vs %s: 同胜 %d, 同负 %d, 仅%s胜 %d, 仅%s胜 %d\n",
+				s.A, s.B, s.BothWon, s.BothLost, s.A, s.OnlyA, s.B, s.OnlyB)
+			fmt.Fprintf(w, "  胜率差 %+.2f%%, McNemar p = %.4g\n", s.WinRateDelta*100, s.PValue)
+			fmt.Fprintf(w, "  %s 赢 %s 输的种子: %v\n", s.A, s.B, s.ExamplesA)
+			fmt.Fprintf(w, "  %s 赢 %s 输的种子: %v\n", s.B, s.A, s.ExamplesB)
+		}
+	}
+}
+
+// mcNemarExact 对不一致对 (b, c) 做 McNemar 精确检验（二项分布 p=0.5）的双侧 p 值
+func mcNemarExact(b, c int) float64 {
+	n := b + c
+	if n == 0 {
+		return 1
+	}
+	k := min(b, c)
+	p := 0.0
+	for i := 0; i <= k; i++ {
+		p += math.Exp(logBinomial(n, i) - float64(n)*math.Ln2)
+	}
+	return math.Min(1, 2*p)
+}
+
+func logBinomial(n, k int) float64 {
+	a, _ := math.Lgamma(float64(n + 1))
+	b, _ := math.Lgamma(float64(k + 1))
+	c, _ := math.Lgamma(float64(n - k + 1))
+	return a - b - c
+}
@@ -0,0 +1,74 @@
+package solver
+
+import (
+	"fmt"
+	"sort"
+)
+
+// GuessStrategy 没有确定解时的猜测策略
+type GuessStrategy int
+
+const (
+	GuessFirstSolution GuessStrategy = iota // 采用第一个解中首个变量的取值
+	GuessLowestRisk                         // 翻开所有解中为雷比例最低的格子
+)
+
+// Config 求解器配置
+type Config struct {
+	Name  string
+	Guess GuessStrategy
+}
+
+// DefaultConfig NewSolver 使用的默认配置
+var DefaultConfig = Config{Name: "first", Guess: GuessFirstSolution}
+
+// configs 按名称注册的预设配置
+var configs = map[string]Config{
+	"first":   DefaultConfig,
+	"lowrisk": {Name: "lowrisk", Guess: GuessLowestRisk},
+}
+
+// ConfigByName 按名称查找预设配置
+func ConfigByName(name string) (Config, error) {
+	config, ok := configs[name]
+	if !ok {
+		return Config{}, fmt.Errorf("未知的求解器配置 %q，可选: %v", name, ConfigNames())
+	}
+	return config, nil
+}
+
+// ConfigNames 返回所有预设配置名称
+func ConfigNames() []string {
+	names := make([]string, 0, len(configs))
+	for name := range configs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// lowestRisk 返回 allowed 允许的变量中在所有解中取 1 比例最低的变量及该比例
+func lowestRisk(solutions [][]int, allowed func(id int) bool) (int, float64, bool) {
+	if len(solutions) == 0 {
+		return 0, 0, false
+	}
+	best, bestRatio := 0, 2.0
+	for id := range solutions[0] {
+		if !allowed(id) {
+			continue
+		}
+		if ratio := mineRatio(solutions, id); ratio < bestRatio {
+			best, bestRatio = id, ratio
+		}
+	}
+	return best, bestRatio, bestRatio <= 1
+}
+
+// mineRatio 返回变量 id 在所有解中取 1 的比例
+func mineRatio(solutions [][]int, id int) float64 {
+	count := 0
+	for _, sol := range solutions {
+		count += sol[id]
+	}
+	return float64(count) / float64(len(solutions))
+}
@@ -0,0 +1,347 @@
+package solver
+
+import (
+	"math"
+	"math/big"
+
+	"minego/internal/cell"
+)
+
+// BuildEquations 根据当前盘面构建约束方程组
+// 所有未知格（包括不与任何数字相邻的格子）都会按行优先顺序分配变量编号，
+// 每个数字格生成一个方程：周围未知格之和 = 数字 - 周围已标记数（含翻开的地雷）
+func (s *solver) BuildEquations() (*PointIDMap, []Equation) {
+	pointID := NewPointIDMap()
+	equations := make([]Equation, 0)
+
+	n := 0
+	for i := range s.field {
+		for j := range s.field[i] {
+			if s.field[i][j].State == cell.Unknown {
+				pointID.Add(s.field[i][j].Position, n)
+				n++
+			}
+		}
+	}
+
+	for i := range s.field {
+		for j := range s.field[i] {
+			ccell := s.field[i][j]
+			if ccell.State < cell.Number1 || ccell.State > cell.Number8 {
+				continue
+			}
+			flaggedCount := 0
+			indices := make([]int, 0, 8)
+			for _, nb := range s.getNeighbors(i, j) {
+				switch nb.State {
+				case cell.Flagged, cell.Mine:
+					flaggedCount++
+				case cell.Unknown:
+					id, _ := pointID.GetID(nb.Position)
+					indices = append(indices, id)
+				}
+			}
+			sum := int(ccell.State) - flaggedCount
+			// 没有未知邻居时，只有和不为 0 的方程才携带信息（矛盾）
+			if len(indices) > 0 || sum != 0 {
+				equations = append(equations, Equation{indices, sum})
+			}
+		}
+	}
+	return pointID, equations
+}
+
+// Len 返回已分配的变量数量
+func (m *PointIDMap) Len() int {
+	return len(m.idToPoint)
+}
+
+// CountSolutions 统计方程组的 0/1 解数量
+// total >= 0 时附加全局约束：所有变量之和等于 total（即剩余地雷数）。
+// 计数达到 limit 时截断（limit <= 0 表示不截断）
+func CountSolutions(n int, equations []Equation, total, limit int) int {
+	comps, free := components(n, equations)
+	if limit > 0 {
+		count, _ := countBounded(comps, free, total, limit, -1)
+		return count
+	}
+	return countAll(comps, free, total)
+}
+
+// CountSolutionsBudget 与 CountSolutions 相同，但搜索节点数超过 budget 时放弃，
+// 此时 ok 为 false，返回的计数不可信
+func CountSolutionsBudget(n int, equations []Equation, total, limit, budget int) (count int, ok bool) {
+	comps, free := components(n, equations)
+	return countBounded(comps, free, total, max(limit, 1), budget)
+}
+
+// countBounded 截断计数：每个分量用单元传播搜索得到"地雷数 -> 解数"的分布，
+// 每个地雷数的计数达到 limit 后不再增加，当子树可能的地雷数都已饱和时直接剪枝，
+// 因此即使分量的解数量巨大，也只需访问很少的解
+// budget 为所有分量共享的搜索节点预算（< 0 表示不限制）
+func countBounded(comps []component, free, total, limit, budget int) (int, bool) {
+	dist := []int{1}
+	for _, comp := range comps {
+		hist := make([]int, len(comp.vars)+1)
+		p := newPropagator(len(comp.vars), comp.equations, 0, len(comp.vars))
+		p.budget = budget
+		p.search(
+			func(mines int) bool {
+				hist[mines] = capAdd(hist[mines], 1, limit)
+				return true
+			},
+			func(minOnes, maxOnes int) bool {
+				for m := minOnes; m <= maxOnes; m++ {
+					if hist[m] < limit {
+						return false
+					}
+				}
+				return true
+			})
+		if p.exhausted {
+			return 0, false
+		}
+		budget = p.budget
+		dist = convolve(dist, hist, limit)
+	}
+	return combine(dist, free, total, limit), true
+}
+
+// countAll 精确计数：分量内回溯枚举得到"地雷数 -> 解数"的分布，
+// 再与不受任何方程约束的变量的组合数合并
+func countAll(comps []component, free, total int) int {
+	dist := []int{1}
+	for _, comp := range comps {
+		hist := make([]int, len(comp.vars)+1)
+		enumerateSolutions(len(comp.vars), comp.equations, func(solution []int) bool {
+			mines := 0
+			for _, v := range solution {
+				mines += v
+			}
+			hist[mines] = capAdd(hist[mines], 1, math.MaxInt)
+			return true
+		})
+		dist = convolve(dist, hist, math.MaxInt)
+	}
+	return combine(dist, free, total, math.MaxInt)
+}
+
+// convolve 合并两个"地雷数 -> 方案数"分布
+func convolve(dist, hist []int, ceiling int) []int {
+	next := make([]int, len(dist)+len(hist)-1)
+	for a, x := range dist {
+		for b, y := range hist {
+			next[a+b] = capAdd(next[a+b], capMul(x, y, ceiling), ceiling)
+		}
+	}
+	return next
+}
+
+// combine 将分量的地雷数分布与自由变量合并，得到满足全局约束的总方案数
+func combine(dist []int, free, total, ceiling int) int {
+	count := 0
+	for m, ways := range dist {
+		if ways == 0 {
+			continue
+		}
+		if total < 0 {
+			count = capAdd(count, capMul(ways, capPow2(free, ceiling), ceiling), ceiling)
+		} else if rest := total - m; rest >= 0 && rest <= free {
+			count = capAdd(count, capMul(ways, capBinomial(free, rest, ceiling), ceiling), ceiling)
+		}
+	}
+	return count
+}
+
+// component 一组通过方程相互关联的变量，方程中的索引已映射为分量内编号
+type component struct {
+	vars      []int
+	equations []Equation
+}
+
+// components 按方程将变量划分为连通分量，并返回未出现在任何方程中的变量数量
+func components(n int, equations []Equation) ([]component, int) {
+	parent := make([]int, n)
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(x int) int {
+		for parent[x] != x {
+			parent[x] = parent[parent[x]]
+			x = parent[x]
+		}
+		return x
+	}
+
+	used := make([]bool, n)
+	for _, eq := range equations {
+		for _, idx := range eq.Indices {
+			used[idx] = true
+			parent[find(idx)] = find(eq.Indices[0])
+		}
+	}
+
+	free := 0
+	rootComp := make(map[int]int)
+	local := make([]int, n)
+	comps := make([]component, 0)
+	for _, v := range bfsOrder(n, equations) {
+		if !used[v] {
+			free++
+			continue
+		}
+		root := find(v)
+		ci, ok := rootComp[root]
+		if !ok {
+			ci = len(comps)
+			rootComp[root] = ci
+			comps = append(comps, component{})
+		}
+		local[v] = len(comps[ci].vars)
+		comps[ci].vars = append(comps[ci].vars, v)
+	}
+
+	for _, eq := range equations {
+		if len(eq.Indices) == 0 {
+			if eq.Sum != 0 {
+				// 矛盾方程：加入一个必然无解的空分量
+				comps = append(comps, component{equations: []Equation{eq}})
+			}
+			continue
+		}
+		ci := rootComp[find(eq.Indices[0])]
+		indices := make([]int, len(eq.Indices))
+		for k, idx := range eq.Indices {
+			indices[k] = local[idx]
+		}
+		comps[ci].equations = append(comps[ci].equations, Equation{indices, eq.Sum})
+	}
+	return comps, free
+}
+
+// bfsOrder 沿方程做广度优先遍历得到变量顺序，使回溯时方程尽早被完全赋值，剪枝更早生效
+func bfsOrder(n int, equations []Equation) []int {
+	varEqs := make([][]int, n)
+	for ei, eq := range equations {
+		for _, idx := range eq.Indices {
+			varEqs[idx] = append(varEqs[idx], ei)
+		}
+	}
+	order := make([]int, 0, n)
+	visited := make([]bool, n)
+	for start := range n {
+		if visited[start] {
+			continue
+		}
+		visited[start] = true
+		queue := []int{start}
+		for len(queue) > 0 {
+			v := queue[0]
+			queue = queue[1:]
+			order = append(order, v)
+			for _, ei := range varEqs[v] {
+				for _, u := range equations[ei].Indices {
+					if !visited[u] {
+						visited[u] = true
+						queue = append(queue, u)
+					}
+				}
+			}
+		}
+	}
+	return order
+}
+
+func capAdd(a, b, ceiling int) int {
+	if a > ceiling-b {
+		return ceiling
+	}
+	return a + b
+}
+
+func capMul(a, b, ceiling int) int {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	if a > ceiling/b {
+		return ceiling
+	}
+	return min(a*b, ceiling)
+}
+
+func capPow2(k, ceiling int) int {
+	result := 1
+	for range k {
+		result = capMul(result, 2, ceiling)
+	}
+	return result
+}
+
+func capBinomial(n, k, ceiling int) int {
+	b := new(big.Int).Binomial(int64(n), int64(k))
+	if !b.IsInt64() || b.Int64() > int64(ceiling) {
+		return ceiling
+	}
+	return int(b.Int64())
+}
+
+// enumerateSolutions 回溯枚举满足方程组的所有 0/1 解，fn 返回 false 时停止枚举
+func enumerateSolutions(n int, equations []Equation, fn func(solution []int) bool) {
+	enumerateSolutionsRange(n, equations, 0, n, fn)
+}
+
+// enumerateSolutionsRange 回溯枚举满足方程组且变量总和位于 [lo, hi] 的所有 0/1 解
+// 每赋值一个变量就检查其所在方程以及全局总和的上下界
+func enumerateSolutionsRange(n int, equations []Equation, lo, hi int, fn func(solution []int) bool) {
+	if lo > n || hi < 0 || lo > hi {
+		return
+	}
+	varEqs := make([][]int, n)
+	sums := make([]int, len(equations))
+	remain := make([]int, len(equations))
+	for ei, eq := range equations {
+		if len(eq.Indices) == 0 && eq.Sum != 0 {
+			return // 无解
+		}
+		for _, idx := range eq.Indices {
+			varEqs[idx] = append(varEqs[idx], ei)
+		}
+		remain[ei] = len(eq.Indices)
+	}
+
+	solution := make([]int, n)
+	total := 0
+	var dfs func(i int) bool
+	dfs = func(i int) bool {
+		if i == n {
+			return fn(solution)
+		}
+		for v := 0; v <= 1; v++ {
+			total += v
+			ok := total <= hi && total+n-i-1 >= lo
+			for _, ei := range varEqs[i] {
+				sums[ei] += v
+				remain[ei]--
+				if sums[ei] > equations[ei].Sum || sums[ei]+remain[ei] < equations[ei].Sum {
+					ok = false
+				}
+			}
+			cont := true
+			if ok {
+				solution[i] = v
+				cont = dfs(i + 1)
+			}
+			for _, ei := range varEqs[i] {
+				sums[ei] -= v
+				remain[ei]++
+			}
+			total -= v
+			if !cont {
+				return false
+			}
+		}
+		return true
+	}
+	dfs(0)
+}
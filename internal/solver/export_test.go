@@ -0,0 +1,4 @@
+package solver
+
+// SolveBinaryEquations 供外部测试包调用
+var SolveBinaryEquations = solveBinaryEquations
@@ -0,0 +1,161 @@
+package solver
+
+import (
+	"image"
+
+	"minego/internal/cell"
+)
+
+// flagSearchBudget 判断嫌疑旗帜能否为地雷时每次搜索的节点预算，耗尽时不做判断
+const flagSearchBudget = 100000
+
+// findWrongFlags 找出必然插错的旗帜。旗帜可能来自用户或此前的误识别，只是未经验证的标记：
+// 与翻开的空白格相邻的旗帜一定是错的；某个数字周围旗帜（含翻开的地雷）多于数字，
+// 或它与附近数字组成的方程组无解时，它周围的旗帜都有嫌疑。
+// 把嫌疑旗帜与未知格一起作为变量、只用相关数字的约束求解，在所有解中都不是地雷的旗帜即为插错。
+// 只用部分约束求解是原问题的放宽，因此判定为插错的旗帜在完整盘面下同样是错的。
+// 找到的插错旗帜会在 s.field 中恢复为未知格
+func (s *solver) findWrongFlags() []image.Point {
+	wrong := make([]image.Point, 0)
+	for i := range s.field {
+		for j := range s.field[i] {
+			if s.field[i][j].State != cell.Empty {
+				continue
+			}
+			for _, nb := range s.neighborPointers(i, j) {
+				if nb.State == cell.Flagged {
+					nb.State = cell.Unknown
+					wrong = append(wrong, nb.Position)
+				}
+			}
+		}
+	}
+
+	// 数字附近 5x5 范围内的数字与它共享未知格，一起求解可以发现单个数字看不出的矛盾
+	suspects := make(map[image.Point]bool)
+	for i := range s.field {
+		for j := range s.field[i] {
+			if !isNumber(s.field[i][j].State) {
+				continue
+			}
+			nearby := make([]image.Point, 0, 25)
+			for r := max(i-2, 0); r <= min(i+2, len(s.field)-1); r++ {
+				for c := max(j-2, 0); c <= min(j+2, len(s.field[r])-1); c++ {
+					if isNumber(s.field[r][c].State) {
+						nearby = append(nearby, image.Point{X: c, Y: r})
+					}
+				}
+			}
+			n, equations := s.flagEquations(nearby, nil)
+			if solvable, ok := findSolution(n, equations); !ok || solvable {
+				continue
+			}
+			for _, nb := range s.neighborPointers(i, j) {
+				if nb.State == cell.Flagged {
+					suspects[nb.Position] = true
+				}
+			}
+		}
+	}
+	if len(suspects) == 0 {
+		return wrong
+	}
+
+	// 与嫌疑旗帜相邻的数字构成方程组；方程组本身无解说明数字可能识别错误，此时不做判断
+	numbers := make([]image.Point, 0)
+	for i := range s.field {
+		for j := range s.field[i] {
+			if !isNumber(s.field[i][j].State) {
+				continue
+			}
+			for _, nb := range s.neighborPointers(i, j) {
+				if suspects[nb.Position] {
+					numbers = append(numbers, image.Point{X: j, Y: i})
+					break
+				}
+			}
+		}
+	}
+	pointID := NewPointIDMap()
+	n, equations := s.flagEquations(numbers, func(p image.Point) int {
+		if !suspects[p] {
+			return -1
+		}
+		id, ok := pointID.GetID(p)
+		if !ok {
+			id = pointID.Len()
+			pointID.Add(p, id)
+		}
+		return id
+	})
+	if solvable, ok := findSolution(n, equations); !ok || !solvable {
+		return wrong
+	}
+	for i := range s.field {
+		for j := range s.field[i] {
+			p := s.field[i][j].Position
+			if !suspects[p] {
+				continue
+			}
+			id, _ := pointID.GetID(p)
+			forced := append(equations[:len(equations):len(equations)], Equation{[]int{id}, 1})
+			if possible, ok := findSolution(n, forced); ok && !possible {
+				s.field[i][j].State = cell.Unknown
+				wrong = append(wrong, p)
+			}
+		}
+	}
+	return wrong
+}
+
+// flagEquations 为 numbers 中的每个数字格建立方程，未知格为变量，旗帜与翻开的地雷计入已确认的地雷。
+// suspect 不为 nil 时先以它为旗帜分配变量编号（返回 -1 表示不是嫌疑旗帜），未知格的编号排在之后
+func (s *solver) flagEquations(numbers []image.Point, suspect func(image.Point) int) (int, []Equation) {
+	ids := make(map[image.Point]int)
+	if suspect != nil {
+		for _, p := range numbers {
+			for _, nb := range s.neighborPointers(p.Y, p.X) {
+				if id := suspect(nb.Position); id >= 0 {
+					ids[nb.Position] = id
+				}
+			}
+		}
+	}
+	n := len(ids)
+	equations := make([]Equation, 0, len(numbers))
+	for _, p := range numbers {
+		sum := int(s.field[p.Y][p.X].State)
+		indices := make([]int, 0, 8)
+		for _, nb := range s.neighborPointers(p.Y, p.X) {
+			if id, ok := ids[nb.Position]; ok {
+				indices = append(indices, id)
+				continue
+			}
+			switch nb.State {
+			case cell.Unknown:
+				ids[nb.Position] = n
+				indices = append(indices, n)
+				n++
+			case cell.Flagged, cell.Mine:
+				sum--
+			}
+		}
+		equations = append(equations, Equation{indices, sum})
+	}
+	return n, equations
+}
+
+// findSolution 判断方程组是否有解，搜索超出 flagSearchBudget 时 ok 为 false
+func findSolution(n int, equations []Equation) (solvable, ok bool) {
+	p := newPropagator(n, equations, 0, n)
+	p.budget = flagSearchBudget
+	p.search(func(int) bool {
+		solvable = true
+		return false
+	}, nil)
+	return solvable, solvable || !p.exhausted
+}
+
+func isNumber(state cell.CellState) bool {
+	return state >= cell.Number1 && state <= cell.Number8
+}
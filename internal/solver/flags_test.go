@@ -0,0 +1,100 @@
+package solver_test
+
+import (
+	"image"
+	"slices"
+	"testing"
+
+	"minego/internal/cell"
+	"minego/internal/identify"
+	"minego/internal/solver"
+)
+
+// parseBoard 按 GridcellRec.txt 的格式解析盘面
+func parseBoard(t *testing.T, text string) [][]cell.GridCell {
+	t.Helper()
+	field, err := identify.ParseResult(text)
+	if err != nil {
+		t.Fatalf("解析盘面失败: %v", err)
+	}
+	return field
+}
+
+func sortedPoints(points []image.Point) []image.Point {
+	sorted := slices.Clone(points)
+	slices.SortFunc(sorted, func(a, b image.Point) int {
+		if a.Y != b.Y {
+			return a.Y - b.Y
+		}
+		return a.X - b.X
+	})
+	return sorted
+}
+
+func TestWrongFlags(t *testing.T) {
+	tests := []struct {
+		name  string
+		board string
+		wrong []image.Point
+	}{
+		{
+			name: "与空白格相邻",
+			board: `E 1 ?
+E F ?
+E 1 ?`,
+			wrong: []image.Point{{X: 1, Y: 1}},
+		},
+		{
+			// 三面旗帜中只有中间一面可能是错的：a+b=1, a+b+c=2, b+c=1
+			name: "与数字矛盾",
+			board: `F F F
+1 2 1
+E E E`,
+			wrong: []image.Point{{X: 1, Y: 0}},
+		},
+		{
+			// 两面旗帜中必有一面是错的，但无法确定是哪一面
+			name: "无法确定",
+			board: `F F
+1 1
+E E`,
+		},
+		{
+			name: "与数字一致",
+			board: `F ? ?
+1 1 ?
+E 1 ?`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := solver.NewSolver(parseBoard(t, tt.board))
+			s.Solve()
+			if got := sortedPoints(s.WrongFlags()); !slices.Equal(got, tt.wrong) {
+				t.Errorf("插错的旗帜 %v, want %v", got, tt.wrong)
+			}
+		})
+	}
+}
+
+func TestWrongFlagsAreSolvedAsUnknown(t *testing.T) {
+	field := parseBoard(t, `F F F
+1 2 1
+E E E`)
+	s := solver.NewSolver(field)
+	safePoints, minePoints := s.Solve()
+	wrong := image.Point{X: 1, Y: 0}
+	if got := s.WrongFlags(); !slices.Equal(got, []image.Point{wrong}) {
+		t.Fatalf("插错的旗帜 %v, want [%v]", got, wrong)
+	}
+	// 取消后的旗帜按未知格求解：两侧的旗帜已满足数字，它是安全的
+	if !slices.Contains(safePoints, wrong) {
+		t.Errorf("安全点 %v 中没有取消的旗帜 %v", safePoints, wrong)
+	}
+	if len(minePoints) != 0 {
+		t.Errorf("雷点 %v, 其余两面旗帜已插好，不应有新的雷点", minePoints)
+	}
+	if field[wrong.Y][wrong.X].State != cell.Flagged {
+		t.Errorf("调用方的盘面被修改为 %s", identify.StateName(field[wrong.Y][wrong.X].State))
+	}
+}
@@ -13,44 +13,18 @@ type Equation struct {
 // solveBinaryEquations 求解二进制方程组
 func solveBinaryEquations(n int, equations []Equation) [][]int {
 	solutions := [][]int{}
-	fmt.Println("n", n)
-	totalStates := 1 << n // 所有可能的状态数 (2^n)
-	fmt.Println("totalStates", totalStates)
-	// 枚举所有可能的二进制状态
-	for state := range totalStates {
-		valid := true
-
-		// 检查是否满足所有方程
-		for _, eq := range equations {
-			sum := 0
-			for _, idx := range eq.Indices {
-				if idx < 0 || idx >= n {
-					panic(fmt.Sprintf("变量索引 %d 超出范围 [0, %d]", idx, n-1))
-				}
-				// 使用位运算检查该变量是否为1
-				if state&(1<<idx) != 0 {
-					sum++
-				}
+	for _, eq := range equations {
+		for _, idx := range eq.Indices {
+			if idx < 0 || idx >= n {
+				panic(fmt.Sprintf("变量索引 %d 超出范围 [0, %d]", idx, n-1))
 			}
-
-			if sum != eq.Sum {
-				valid = false
-				break
-			}
-		}
-
-		// 如果满足所有方程，保存解
-		if valid {
-			solution := make([]int, n)
-			for i := 0; i < n; i++ {
-				if state&(1<<i) != 0 {
-					solution[i] = 1
-				} // 默认为0
-			}
-			solutions = append(solutions, solution)
 		}
 	}
-
+	// 回溯枚举，比穷举全部 2^n 个状态快得多
+	enumerateSolutions(n, equations, func(solution []int) bool {
+		solutions = append(solutions, append([]int(nil), solution...))
+		return true
+	})
 	return solutions
 }
 
@@ -0,0 +1,83 @@
+package solver_test
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"minego/internal/solver"
+)
+
+// bruteForce 穷举全部 2^n 个状态，作为回溯枚举的对照
+func bruteForce(n int, equations []solver.Equation) []string {
+	var solutions []string
+	for state := range 1 << n {
+		valid := true
+		for _, eq := range equations {
+			sum := 0
+			for _, idx := range eq.Indices {
+				sum += state >> idx & 1
+			}
+			if sum != eq.Sum {
+				valid = false
+				break
+			}
+		}
+		if valid {
+			solution := make([]int, n)
+			for i := range n {
+				solution[i] = state >> i & 1
+			}
+			solutions = append(solutions, fmt.Sprint(solution))
+		}
+	}
+	return solutions
+}
+
+func keys(solutions [][]int) []string {
+	result := make([]string, len(solutions))
+	for i, s := range solutions {
+		result[i] = fmt.Sprint(s)
+	}
+	slices.Sort(result)
+	return result
+}
+
+func TestSolveBinaryEquationsMatchesBruteForce(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	for k := range 500 {
+		n := 1 + rng.IntN(10)
+		equations := make([]solver.Equation, 1+rng.IntN(6))
+		for e := range equations {
+			// 每个方程取至多 8 个不同变量，与扫雷数字的邻格数一致
+			indices := rng.Perm(n)[:1+rng.IntN(min(n, 8))]
+			equations[e] = solver.Equation{Indices: indices, Sum: rng.IntN(len(indices) + 1)}
+		}
+		want := bruteForce(n, equations)
+		slices.Sort(want)
+		if got := keys(solver.SolveBinaryEquations(n, equations)); !slices.Equal(got, want) {
+			t.Fatalf("第 %d 组 n=%d %v: 解 %v, want %v", k, n, equations, got, want)
+		}
+	}
+}
+
+func TestSolveBinaryEquationsLongChain(t *testing.T) {
+	// 60 个变量的链式约束 x[i] + x[i+1] = 1，穷举 2^60 个状态不可行，只有交替的两组解
+	const n = 60
+	var equations []solver.Equation
+	for i := range n - 1 {
+		equations = append(equations, solver.Equation{Indices: []int{i, i + 1}, Sum: 1})
+	}
+	solutions := solver.SolveBinaryEquations(n, equations)
+	if len(solutions) != 2 {
+		t.Fatalf("解的数量 %d, want 2", len(solutions))
+	}
+	for _, s := range solutions {
+		for i := range n - 1 {
+			if s[i]+s[i+1] != 1 {
+				t.Fatalf("解 %v 不满足 x%d + x%d = 1", s, i, i+1)
+			}
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package solver
+
+// propagator 带单元传播的 0/1 方程组搜索器
+// 每次赋值后反复检查相关方程：已满足的方程将剩余变量置 0，
+// 剩余变量必须全为雷的方程将其置 1，出现矛盾时立即回溯
+type propagator struct {
+	n         int
+	equations []Equation
+	lo, hi    int // 所有变量之和的上下界
+
+	varEqs  [][]int
+	assign  []int8 // -1 未赋值
+	sums    []int  // 每个方程已赋值为 1 的数量
+	remain  []int  // 每个方程未赋值变量数量
+	ones    int    // 全部变量中已赋值为 1 的数量
+	unknown int    // 全部变量中未赋值数量
+	trail   []int  // 赋值顺序，用于回溯
+
+	budget    int  // 剩余搜索节点数，< 0 表示不限制
+	exhausted bool // 是否因预算耗尽而提前结束
+}
+
+func newPropagator(n int, equations []Equation, lo, hi int) *propagator {
+	p := &propagator{
+		n:         n,
+		equations: equations,
+		lo:        lo,
+		hi:        hi,
+		varEqs:    make([][]int, n),
+		assign:    make([]int8, n),
+		sums:      make([]int, len(equations)),
+		remain:    make([]int, len(equations)),
+		unknown:   n,
+		budget:    -1,
+	}
+	for i := range p.assign {
+		p.assign[i] = -1
+	}
+	for ei, eq := range equations {
+		for _, idx := range eq.Indices {
+			p.varEqs[idx] = append(p.varEqs[idx], ei)
+		}
+		p.remain[ei] = len(eq.Indices)
+	}
+	return p
+}
+
+// search 枚举所有解，每找到一个解调用 fn(解中 1 的数量)，fn 返回 false 时停止
+// prune 可为 nil，否则在进入子树前以子树可能的 1 的数量范围调用，返回 true 时跳过该子树
+func (p *propagator) search(fn func(ones int) bool, prune func(minOnes, maxOnes int) bool) {
+	mark := len(p.trail)
+	all := make([]int, len(p.equations))
+	for ei := range all {
+		all[ei] = ei
+	}
+	if p.propagate(all) {
+		p.dfs(fn, prune)
+	}
+	p.undo(mark)
+}
+
+func (p *propagator) dfs(fn func(ones int) bool, prune func(minOnes, maxOnes int) bool) bool {
+	if p.budget == 0 {
+		p.exhausted = true
+		return false
+	}
+	if p.budget > 0 {
+		p.budget--
+	}
+	if prune != nil && prune(max(p.ones, p.lo), min(p.ones+p.unknown, p.hi)) {
+		return true
+	}
+	v := p.pick()
+	if v < 0 {
+		return fn(p.ones)
+	}
+	for val := int8(0); val <= 1; val++ {
+		mark := len(p.trail)
+		ok := p.set(v, val) && p.propagate(p.varEqs[v])
+		cont := true
+		if ok {
+			cont = p.dfs(fn, prune)
+		}
+		p.undo(mark)
+		if !cont {
+			return false
+		}
+	}
+	return true
+}
+
+// pick 选择未赋值变量最少的方程中的一个变量，没有方程约束时按编号选择
+func (p *propagator) pick() int {
+	best, bestRemain := -1, 0
+	for ei, eq := range p.equations {
+		if p.remain[ei] == 0 || (best >= 0 && p.remain[ei] >= bestRemain) {
+			continue
+		}
+		for _, idx := range eq.Indices {
+			if p.assign[idx] < 0 {
+				best, bestRemain = idx, p.remain[ei]
+				break
+			}
+		}
+	}
+	if best >= 0 {
+		return best
+	}
+	for v := range p.n {
+		if p.assign[v] < 0 {
+			return v
+		}
+	}
+	return -1
+}
+
+// set 赋值单个变量并检查全局上下界
+func (p *propagator) set(v int, val int8) bool {
+	p.assign[v] = val
+	p.trail = append(p.trail, v)
+	p.unknown--
+	p.ones += int(val)
+	for _, ei := range p.varEqs[v] {
+		p.sums[ei] += int(val)
+		p.remain[ei]--
+	}
+	return p.ones <= p.hi && p.ones+p.unknown >= p.lo
+}
+
+func (p *propagator) undo(mark int) {
+	for len(p.trail) > mark {
+		v := p.trail[len(p.trail)-1]
+		p.trail = p.trail[:len(p.trail)-1]
+		val := int(p.assign[v])
+		p.assign[v] = -1
+		p.unknown++
+		p.ones -= val
+		for _, ei := range p.varEqs[v] {
+			p.sums[ei] -= val
+			p.remain[ei]++
+		}
+	}
+}
+
+// propagate 从给定方程出发做单元传播，返回 false 表示出现矛盾
+func (p *propagator) propagate(queue []int) bool {
+	queue = append([]int(nil), queue...)
+	for len(queue) > 0 {
+		ei := queue[len(queue)-1]
+		queue = queue[:len(queue)-1]
+		eq := p.equations[ei]
+		sum, remain := p.sums[ei], p.remain[ei]
+		if sum > eq.Sum || sum+remain < eq.Sum {
+			return false
+		}
+		if remain == 0 || (sum != eq.Sum && sum+remain != eq.Sum) {
+			continue
+		}
+		val := int8(0)
+		if sum != eq.Sum {
+			val = 1
+		}
+		for _, idx := range eq.Indices {
+			if p.assign[idx] >= 0 {
+				continue
+			}
+			if !p.set(idx, val) {
+				return false
+			}
+			queue = append(queue, p.varEqs[idx]...)
+		}
+	}
+	return true
+}
@@ -8,27 +8,74 @@ import (
 
 // solver 扫雷求解器
 type solver struct {
-	field [][]cell.GridCell
+	field      [][]cell.GridCell
+	config     Config
+	lastGuess  *Guess
+	wrongFlags []image.Point
+	unobserved map[image.Point]bool // 无法观测的格子，求解时视为未知格，但不会输出为安全点或地雷
+}
+
+// Guess 没有确定解时翻开的格子以及求解器估计的地雷概率
+type Guess struct {
+	Point       image.Point
+	Probability float64
 }
 
 func NewSolver(field [][]cell.GridCell) *solver {
-	return &solver{field: field}
+	return NewSolverWithConfig(field, DefaultConfig)
+}
+
+// NewSolverWithConfig 使用指定配置创建求解器
+func NewSolverWithConfig(field [][]cell.GridCell, config Config) *solver {
+	copied, unobserved := coveredAsUnknown(field)
+	return &solver{field: copied, config: config, unobserved: unobserved}
+}
+
+// coveredAsUnknown 返回盘面的副本，"?" 标记与无法观测的格子视为未知格，同时返回无法观测的格子。
+// 无法观测的格子实际可能是数字或旗帜，把它当作未知格只会放宽约束，推出的结论仍然成立。
+// 求解过程中会修改盘面（推出的地雷记为旗帜、插错的旗帜恢复为未知），调用方的盘面保持不变
+func coveredAsUnknown(field [][]cell.GridCell) ([][]cell.GridCell, map[image.Point]bool) {
+	copied := make([][]cell.GridCell, len(field))
+	unobserved := make(map[image.Point]bool)
+	for r := range field {
+		copied[r] = make([]cell.GridCell, len(field[r]))
+		for c, gc := range field[r] {
+			switch gc.State {
+			case cell.Unobserved:
+				unobserved[gc.Position] = true
+				gc.State = cell.Unknown
+			case cell.QuestionMark:
+				gc.State = cell.Unknown
+			}
+			copied[r][c] = gc
+		}
+	}
+	return copied, unobserved
 }
 
-// Solve 实现扫雷求解逻辑
+// Solve 实现扫雷求解逻辑，插错的旗帜视为未知格参与求解，可通过 WrongFlags 获取。
+// 无法观测的格子不会出现在返回的安全点与地雷中
 func (s *solver) Solve() ([]image.Point, []image.Point) {
+	s.lastGuess = nil
+	s.wrongFlags = s.findWrongFlags()
 	var safePoints []image.Point
 	var minePoints []image.Point
 	safeSet := make(map[image.Point]struct{})
 	mineSet := make(map[image.Point]struct{})
 
 	addSafe := func(p image.Point) {
+		if s.unobserved[p] {
+			return
+		}
 		if _, exists := safeSet[p]; !exists {
 			safePoints = append(safePoints, p)
 			safeSet[p] = struct{}{}
 		}
 	}
 	addMine := func(p image.Point) {
+		if s.unobserved[p] {
+			return
+		}
 		if _, exists := mineSet[p]; !exists {
 			minePoints = append(minePoints, p)
 			mineSet[p] = struct{}{}
@@ -53,12 +100,12 @@ func (s *solver) Solve() ([]image.Point, []image.Point) {
 			unknownCount := 0
 			flaggedCount := 0
 
-			// 单次遍历统计未知和标记数量
+			// 单次遍历统计未知和标记数量（翻开的地雷视为已标记）
 			for _, neighborCell := range neighbors {
 				switch neighborCell.State {
-				case cell.Unknown, cell.Flagged:
+				case cell.Unknown, cell.Flagged, cell.Mine:
 					unknownCount++
-					if neighborCell.State == cell.Flagged {
+					if neighborCell.State != cell.Unknown {
 						flaggedCount++
 					}
 				}
@@ -75,19 +122,13 @@ func (s *solver) Solve() ([]image.Point, []image.Point) {
 				continue
 			}
 
-			// 标记所有未知为安全
+			// 标记所有未知为安全；旗帜多于数字时无法判断（插错的旗帜已由 findWrongFlags 处理）
 			if flaggedCount == int(currentCell.State) {
 				for _, neighborCell := range neighbors {
 					if neighborCell.State == cell.Unknown {
 						addSafe(neighborCell.Position)
 					}
 				}
-			} else if flaggedCount > int(currentCell.State) {
-				for _, nb := range neighbors {
-					if nb.State == cell.Flagged {
-						addMine(nb.Position)
-					}
-				}
 			}
 		}
 	}
@@ -107,7 +148,7 @@ Loop:
 			neighbors := s.getNeighbors(i, j)
 			s := 0
 			for _, nb := range neighbors {
-				if nb.State == cell.Flagged || nb.State == cell.Empty {
+				if nb.State == cell.Flagged || nb.State == cell.Mine || nb.State == cell.Empty {
 					s += 1
 				}
 			}
@@ -116,7 +157,7 @@ Loop:
 			}
 			unknowncells := make([]int, 0)
 			for _, nb := range neighbors {
-				if nb.State == cell.Flagged {
+				if nb.State == cell.Flagged || nb.State == cell.Mine {
 					flaggedCount += 1
 				}
 				if nb.State == cell.Unknown {
@@ -142,14 +183,24 @@ Loop:
 	// 求解方程组
 
 	res := solveBinaryEquations(n, equations)
-	// fmt.Println("res", res)
 	// 处理结果
 	samep := comparePositions(res)
 	if usefulEle(samep) == 0 && len(safePoints) == 0 && len(minePoints) == 0 {
-		if len(res) >= 1 && len(res[0]) >= 1 {
-			samep = []int{res[0][0]}
+		switch s.config.Guess {
+		case GuessLowestRisk:
+			if id, p, ok := lowestRisk(res, func(id int) bool { return !s.unobserved[pointID.idToPoint[id]] }); ok {
+				addSafe(pointID.idToPoint[id])
+				s.lastGuess = &Guess{Point: pointID.idToPoint[id], Probability: p}
+			}
+			samep = nil
+		default:
+			if len(res) >= 1 && len(res[0]) >= 1 {
+				samep = []int{res[0][0]}
+				if res[0][0] == 0 && !s.unobserved[pointID.idToPoint[0]] {
+					s.lastGuess = &Guess{Point: pointID.idToPoint[0], Probability: mineRatio(res, 0)}
+				}
+			}
 		}
-
 	}
 	for id, p := range samep {
 		point := pointID.idToPoint[id]
@@ -166,6 +217,19 @@ Loop:
 	return safePoints, minePoints
 }
 
+// WrongFlags 返回最近一次 Solve 判定为插错、需要右键取消的旗帜
+func (s *solver) WrongFlags() []image.Point {
+	return s.wrongFlags
+}
+
+// LastGuess 返回最近一次 Solve 中作为安全点输出的猜测
+func (s *solver) LastGuess() (Guess, bool) {
+	if s.lastGuess == nil {
+		return Guess{}, false
+	}
+	return *s.lastGuess, true
+}
+
 func usefulEle(arr []int) int {
 	count := 0
 	for _, v := range arr {
@@ -0,0 +1,88 @@
+package solver_test
+
+import (
+	"image"
+	"slices"
+	"testing"
+
+	"minego/internal/solver"
+)
+
+// solveCase 一个盘面及其确定的安全点与地雷
+type solveCase struct {
+	name  string
+	board string
+	safe  []image.Point
+	mines []image.Point
+}
+
+func runSolveCases(t *testing.T, tests []solveCase) {
+	t.Helper()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			safePoints, minePoints := solver.NewSolver(parseBoard(t, tt.board)).Solve()
+			if got := sortedPoints(safePoints); !slices.Equal(got, tt.safe) {
+				t.Errorf("安全点 %v, want %v", got, tt.safe)
+			}
+			if got := sortedPoints(minePoints); !slices.Equal(got, tt.mines) {
+				t.Errorf("雷点 %v, want %v", got, tt.mines)
+			}
+		})
+	}
+}
+
+func TestSolveMineCountsAsKnownMine(t *testing.T) {
+	runSolveCases(t, []solveCase{
+		{
+			name: "数字已由翻开的地雷满足",
+			board: `M ?
+1 1
+E E`,
+			safe: []image.Point{{X: 1, Y: 0}},
+		},
+		{
+			name: "计入数字周围的地雷数",
+			board: `M ? ?
+2 2 1
+E E E`,
+			// 左侧的 2 还差一个地雷，中间的 2 因此已满足
+			safe:  []image.Point{{X: 2, Y: 0}},
+			mines: []image.Point{{X: 1, Y: 0}},
+		},
+	})
+}
+
+func TestSolveCoveredMarks(t *testing.T) {
+	runSolveCases(t, []solveCase{
+		{
+			name: "问号按未知格求解",
+			board: `Q ?
+2 2
+E E`,
+			mines: []image.Point{{X: 0, Y: 0}, {X: 1, Y: 0}},
+		},
+		{
+			name: "问号可以是安全点",
+			board: `M Q
+1 1
+E E`,
+			safe: []image.Point{{X: 1, Y: 0}},
+		},
+		{
+			// 无法观测的格子参与约束，但推出它是地雷时也不输出
+			name: "无法观测的地雷",
+			board: `- ?
+2 2
+E E`,
+			mines: []image.Point{{X: 1, Y: 0}},
+		},
+		{
+			// 左侧的 1 推出无法观测的格子安全，其余数字因此推出 ? 是地雷
+			name: "无法观测的安全格",
+			board: `M - ?
+1 2 1
+E E E`,
+			mines: []image.Point{{X: 2, Y: 0}},
+		},
+	})
+}
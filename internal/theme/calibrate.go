@@ -0,0 +1,270 @@
+package theme
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"slices"
+	"sort"
+
+	"minego/internal/cell"
+	"minego/internal/identify"
+	"minego/pkg/colorutil"
+	"minego/pkg/imageproc"
+)
+
+const (
+	distinctTolerance = 30 // 特征色与其他状态中出现的颜色的最小 L1 距离（8 位）
+	maxCandidates     = 20 // 每种状态最多尝试的候选特征色
+)
+
+// Calibration 标定结果
+type Calibration struct {
+	Theme           *Theme
+	Templates       *identify.TemplateClassifier // 新开局标定时为 nil
+	HorizontalLines []int
+	VerticalLines   []int
+}
+
+// Calibrate 从已知布局的雷区截图推导主题与识别模板。
+// labels 为 nil 时视为全部未翻开的新开局，此时只能得到边框、覆盖格颜色与二值化阈值，不生成模板
+func Calibrate(img image.Image, labels [][]cell.GridCell) (*Calibration, error) {
+	horizontalLines, verticalLines, err := findGrid(img, labels)
+	if err != nil {
+		return nil, err
+	}
+	fresh := labels == nil
+	if fresh {
+		labels = make([][]cell.GridCell, len(horizontalLines)-1)
+		for i := range labels {
+			labels[i] = make([]cell.GridCell, len(verticalLines)-1)
+			for j := range labels[i] {
+				labels[i][j] = cell.GridCell{State: cell.Unknown, Position: image.Point{X: j, Y: i}}
+			}
+		}
+	}
+
+	t := &Theme{
+		Name:        "calibrated",
+		BorderColor: NewColor(lineColor(img, horizontalLines, verticalLines)),
+		CellSize:    math.Round(float64(verticalLines[len(verticalLines)-1]-verticalLines[0])/float64(len(verticalLines)-1)*10) / 10,
+	}
+
+	// 按状态统计单元格内部的颜色直方图
+	hists := make(map[cell.CellState]map[color.RGBA]int)
+	for i, row := range labels {
+		for j, c := range row {
+			hist, ok := hists[c.State]
+			if !ok {
+				hist = make(map[color.RGBA]int)
+				hists[c.State] = hist
+			}
+			x := (verticalLines[j] + verticalLines[j+1]) / 2
+			y := (horizontalLines[i] + horizontalLines[i+1]) / 2
+			accumulate(hist, identify.CellCrop(img, x, y, verticalLines[j+1]-verticalLines[j], horizontalLines[i+1]-horizontalLines[i]))
+		}
+	}
+
+	if hist, ok := hists[cell.Unknown]; ok {
+		t.CoveredColor = NewColor(mode(hist))
+		t.CoveredRange = coveredRange(img, labels, horizontalLines, verticalLines, mode(hist))
+	}
+	if hist, ok := hists[cell.Empty]; ok {
+		t.RevealedColor = NewColor(mode(hist))
+	}
+	for n := 1; n <= 8; n++ {
+		if c, ok := distinctive(hists, cell.Empty+cell.CellState(n)); ok {
+			if t.NumberColors == nil {
+				t.NumberColors = make(map[int]Color)
+			}
+			t.NumberColors[n] = NewColor(c)
+		}
+	}
+	if c, ok := distinctive(hists, cell.Flagged); ok {
+		t.FlaggedColor = NewColor(c)
+	}
+	// 踩中的地雷与插错的旗帜上同样画着地雷
+	if c, ok := distinctive(hists, cell.Mine, cell.Exploded, cell.Misflag); ok {
+		t.MineColor = NewColor(c)
+	}
+	// 踩雷格的背景色常与旗帜同为红色，不要求独有，取出现最多的颜色
+	if hist, ok := hists[cell.Exploded]; ok {
+		t.ExplodedColor = NewColor(mode(hist))
+	}
+	if c, ok := distinctive(hists, cell.Misflag); ok {
+		t.MisflagColor = NewColor(c)
+	}
+	if c, ok := distinctive(hists, cell.QuestionMark); ok {
+		t.QuestionColor = NewColor(c)
+	}
+
+	if t.CoveredColor.IsSet() && t.RevealedColor.IsSet() && t.RevealedColor.R > t.CoveredColor.R {
+		t.EmptyMinRed = uint8((int(t.CoveredColor.R) + int(t.RevealedColor.R)) / 2)
+	}
+
+	// 阈值取网格线与最暗单元格背景灰度的中点
+	background := 255
+	for _, c := range []Color{t.CoveredColor, t.RevealedColor} {
+		if c.IsSet() {
+			background = min(background, int(imageproc.Gray(c)))
+		}
+	}
+	line := int(imageproc.Gray(t.BorderColor))
+	if background <= line {
+		return nil, fmt.Errorf("网格线灰度 %d 不低于单元格背景灰度 %d，无法确定二值化阈值", line, background)
+	}
+	t.BinarizeThreshold = uint8((line + background) / 2)
+
+	calibration := &Calibration{Theme: t, HorizontalLines: horizontalLines, VerticalLines: verticalLines}
+	if !fresh {
+		calibration.Templates = identify.ExtractTemplates(img, horizontalLines, verticalLines, labels)
+	}
+	return calibration, nil
+}
+
+// findGrid 先按周期检测网格，再依次用当前阈值、Otsu 阈值与自适应阈值检测网格线，
+// 行列数与标注不一致时依次尝试其他固定阈值
+func findGrid(img image.Image, labels [][]cell.GridCell) ([]int, []int, error) {
+	matches := func(h, v []int) bool {
+		if len(h) < 2 || len(v) < 2 {
+			return false
+		}
+		return labels == nil || (len(h)-1 == len(labels) && len(v)-1 == len(labels[0]))
+	}
+	if h, v, err := imageproc.DetectGridByPeriod(img); err == nil && matches(h, v) {
+		return h, v, nil
+	}
+	if h, v := imageproc.DetectMineSweeperGridWithThreshold(img, imageproc.BinarizeThreshold); matches(h, v) {
+		return h, v, nil
+	}
+	for _, mode := range []imageproc.ThresholdMode{imageproc.ThresholdOtsu, imageproc.ThresholdAdaptive} {
+		if grid := imageproc.DetectGrid(img, mode); matches(grid.HorizontalLines, grid.VerticalLines) {
+			return grid.HorizontalLines, grid.VerticalLines, nil
+		}
+	}
+	for threshold := 20; threshold <= 220; threshold += 10 {
+		if h, v := imageproc.DetectMineSweeperGridWithThreshold(img, uint8(threshold)); matches(h, v) {
+			return h, v, nil
+		}
+	}
+	if labels != nil {
+		return nil, nil, fmt.Errorf("未能检测到与标注一致的 %dx%d 网格", len(labels), len(labels[0]))
+	}
+	return nil, nil, fmt.Errorf("未能检测到雷区网格")
+}
+
+// coveredRange 返回颜色识别器把每个覆盖格都识别为覆盖格所需的距离上限：
+// 各覆盖格中心探测窗口内与主色最接近的像素距离（8 位 L1）的最大值，再留出 distinctTolerance 的余量
+func coveredRange(img image.Image, labels [][]cell.GridCell, horizontalLines, verticalLines []int, covered color.RGBA) int {
+	b := img.Bounds()
+	worst := 0
+	for i, row := range labels {
+		for j, c := range row {
+			if c.State != cell.Unknown {
+				continue
+			}
+			x := (verticalLines[j] + verticalLines[j+1]) / 2
+			y := (horizontalLines[i] + horizontalLines[i+1]) / 2
+			rang := (verticalLines[j+1] - verticalLines[j]) / 6
+			nearest := math.MaxInt
+			for dy := -rang; dy <= rang; dy++ {
+				for dx := -rang; dx <= rang; dx++ {
+					nearest = min(nearest, colorutil.ColorsDist(img.At(b.Min.X+x+dx, b.Min.Y+y+dy), covered)/257)
+				}
+			}
+			worst = max(worst, nearest)
+		}
+	}
+	return worst + distinctTolerance
+}
+
+// lineColor 返回网格线上出现最多的颜色
+func lineColor(img image.Image, horizontalLines, verticalLines []int) color.RGBA {
+	b := img.Bounds()
+	hist := make(map[color.RGBA]int)
+	for _, y := range horizontalLines {
+		for x := verticalLines[0]; x <= verticalLines[len(verticalLines)-1]; x++ {
+			hist[rgba(img.At(b.Min.X+x, b.Min.Y+y))]++
+		}
+	}
+	for _, x := range verticalLines {
+		for y := horizontalLines[0]; y <= horizontalLines[len(horizontalLines)-1]; y++ {
+			hist[rgba(img.At(b.Min.X+x, b.Min.Y+y))]++
+		}
+	}
+	return mode(hist)
+}
+
+// distinctive 返回 state 格子中出现最多、且在其他状态格子中都没有相近颜色的颜色，shared 中的状态允许含有该颜色
+func distinctive(hists map[cell.CellState]map[color.RGBA]int, state cell.CellState, shared ...cell.CellState) (color.RGBA, bool) {
+	hist, ok := hists[state]
+	if !ok {
+		return color.RGBA{}, false
+	}
+	others := make([]color.RGBA, 0)
+	for s, h := range hists {
+		if s == state || slices.Contains(shared, s) {
+			continue
+		}
+		for c, count := range h {
+			if count >= 2 { // 忽略噪声造成的零星颜色
+				others = append(others, c)
+			}
+		}
+	}
+
+	for _, candidate := range sortedColors(hist)[:min(len(hist), maxCandidates)] {
+		unique := true
+		for _, c := range others {
+			if colorutil.ColorsCloseN(candidate, c, distinctTolerance) {
+				unique = false
+				break
+			}
+		}
+		if unique {
+			return candidate, true
+		}
+	}
+	return color.RGBA{}, false
+}
+
+func accumulate(hist map[color.RGBA]int, img image.Image) {
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			hist[rgba(img.At(x, y))]++
+		}
+	}
+}
+
+func mode(hist map[color.RGBA]int) color.RGBA {
+	colors := sortedColors(hist)
+	if len(colors) == 0 {
+		return color.RGBA{}
+	}
+	return colors[0]
+}
+
+// sortedColors 按出现次数从多到少排序，次数相同时按颜色值排序以保证结果稳定
+func sortedColors(hist map[color.RGBA]int) []color.RGBA {
+	colors := make([]color.RGBA, 0, len(hist))
+	for c := range hist {
+		colors = append(colors, c)
+	}
+	sort.Slice(colors, func(a, b int) bool {
+		ca, cb := hist[colors[a]], hist[colors[b]]
+		if ca != cb {
+			return ca > cb
+		}
+		ka := uint32(colors[a].R)<<16 | uint32(colors[a].G)<<8 | uint32(colors[a].B)
+		kb := uint32(colors[b].R)<<16 | uint32(colors[b].G)<<8 | uint32(colors[b].B)
+		return ka < kb
+	})
+	return colors
+}
+
+func rgba(c color.Color) color.RGBA {
+	r, g, b, _ := c.RGBA()
+	return color.RGBA{uint8(r >> 8), uint8(g >> 8), uint8(b >> 8), 255}
+}
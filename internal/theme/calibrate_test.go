@@ -0,0 +1,161 @@
+package theme_test
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"minego/internal/cell"
+	"minego/internal/identify"
+	"minego/internal/imgpos"
+	"minego/internal/render"
+	"minego/internal/theme"
+	"minego/pkg/imageproc"
+)
+
+// allStates 标注盘面中循环使用的状态，每种至少出现两次
+var allStates = []cell.CellState{
+	cell.Unknown, cell.Empty, cell.Number1, cell.Number2, cell.Number3, cell.Number4, cell.Number5, cell.Number6,
+	cell.Flagged, cell.Mine, cell.Exploded, cell.Misflag, cell.QuestionMark,
+}
+
+func labelledBoard(rows, cols int) [][]cell.GridCell {
+	grid := make([][]cell.GridCell, rows)
+	for i := range grid {
+		grid[i] = make([]cell.GridCell, cols)
+		for j := range grid[i] {
+			grid[i][j] = cell.GridCell{State: allStates[(i*cols+j)%len(allStates)], Position: image.Point{X: j, Y: i}}
+		}
+	}
+	return grid
+}
+
+// restoreGlobals 测试结束时恢复主题会修改的全局参数
+func restoreGlobals(t *testing.T) {
+	defaults := theme.Default()
+	cellSize := imageproc.CellSize
+	t.Cleanup(func() {
+		if err := defaults.Apply(); err != nil {
+			t.Errorf("恢复默认主题失败: %v", err)
+		}
+		imageproc.CellSize = cellSize
+	})
+}
+
+func TestCalibrateFeatureColors(t *testing.T) {
+	restoreGlobals(t)
+	// 模拟真实客户端：特征色与内置的占位值不同
+	captured := map[string]color.RGBA{
+		"mine":     {40, 40, 70, 255},
+		"exploded": {200, 30, 30, 255},
+		"misflag":  {230, 60, 200, 255},
+		"question": {20, 160, 120, 255},
+	}
+	identify.MineColor = captured["mine"]
+	identify.ExplodedColor = captured["exploded"]
+	identify.MisflagColor = captured["misflag"]
+	identify.QuestionMarkColor = captured["question"]
+	labels := labelledBoard(4, 13)
+	board := render.Board(labels, render.DefaultCellSize)
+	if err := theme.Default().Apply(); err != nil {
+		t.Fatal(err)
+	}
+	// 恢复为占位值后，颜色识别器读不出这些状态
+	identify.MineColor = color.RGBA{17, 17, 17, 255}
+	identify.ExplodedColor = color.RGBA{236, 80, 64, 255}
+	identify.MisflagColor = color.RGBA{250, 140, 0, 255}
+	identify.QuestionMarkColor = color.RGBA{255, 220, 60, 255}
+
+	calibration, err := theme.Calibrate(board, labels)
+	if err != nil {
+		t.Fatalf("标定失败: %v", err)
+	}
+	got := calibration.Theme
+	for name, c := range map[string]theme.Color{
+		"mine": got.MineColor, "exploded": got.ExplodedColor, "misflag": got.MisflagColor, "question": got.QuestionColor,
+	} {
+		if color.RGBA(c) != captured[name] {
+			t.Errorf("%s 标定为 %v, want %v", name, color.RGBA(c), captured[name])
+		}
+	}
+
+	if err := got.Apply(); err != nil {
+		t.Fatalf("应用标定的主题失败: %v", err)
+	}
+	horizontalLines, verticalLines := render.Lines(len(labels), len(labels[0]), render.DefaultCellSize)
+	cells := identify.IdentifyWith(identify.ColorRecognizer{}, imgpos.NewImageWithOffset(board, image.Point{}), horizontalLines, verticalLines)
+	for i, row := range labels {
+		for j, c := range row {
+			if cells[i][j].State != c.State {
+				t.Errorf("第 %d 行第 %d 列 %s 识别为 %s", i+1, j+1, identify.StateName(c.State), identify.StateName(cells[i][j].State))
+			}
+		}
+	}
+}
+
+func TestApplyWithoutTemplatesKeepsColorRecognizer(t *testing.T) {
+	restoreGlobals(t)
+	recognizer := identify.DefaultRecognizer
+	t.Cleanup(func() { identify.DefaultRecognizer = recognizer })
+	t.Chdir(t.TempDir())
+
+	for _, name := range []string{"xp", "arbiter", "msx"} {
+		profile, err := theme.ProfileByName(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		identify.DefaultRecognizer = identify.ColorRecognizer{}
+		if profile.Theme.HasTemplates() {
+			t.Fatalf("%s: 空目录中不应有模板", name)
+		}
+		if err := profile.Theme.Apply(); err != nil {
+			t.Errorf("%s 没有模板时应用失败: %v", name, err)
+		}
+		if _, ok := identify.DefaultRecognizer.(identify.ColorRecognizer); !ok {
+			t.Errorf("%s 没有模板时识别器为 %T，应保留颜色识别器", name, identify.DefaultRecognizer)
+		}
+	}
+}
+
+func TestCalibrateCoveredRange(t *testing.T) {
+	restoreGlobals(t)
+	// 渲染时覆盖格使用与内置占位值相差很远的颜色
+	labels := labelledBoard(4, 13)
+	covered := render.CoveredColor
+	render.CoveredColor = color.RGBA{150, 60, 40, 255}
+	board := render.Board(labels, render.DefaultCellSize)
+	render.CoveredColor = covered
+
+	horizontalLines, verticalLines := render.Lines(len(labels), len(labels[0]), render.DefaultCellSize)
+	identifyUnknown := func() (unknown, total int) {
+		cells := identify.IdentifyWith(identify.ColorRecognizer{}, imgpos.NewImageWithOffset(board, image.Point{}), horizontalLines, verticalLines)
+		for i, row := range labels {
+			for j, c := range row {
+				if c.State == cell.Unknown {
+					total++
+					if cells[i][j].State == cell.Unknown {
+						unknown++
+					}
+				}
+			}
+		}
+		return unknown, total
+	}
+	if unknown, _ := identifyUnknown(); unknown != 0 {
+		t.Fatalf("未标定时 %d 个覆盖格识别为覆盖格，测试前提不成立", unknown)
+	}
+
+	calibration, err := theme.Calibrate(board, labels)
+	if err != nil {
+		t.Fatalf("标定失败: %v", err)
+	}
+	if got := color.RGBA(calibration.Theme.CoveredColor); got != (color.RGBA{150, 60, 40, 255}) {
+		t.Errorf("覆盖格颜色标定为 %v", got)
+	}
+	if err := calibration.Theme.Apply(); err != nil {
+		t.Fatal(err)
+	}
+	if unknown, total := identifyUnknown(); unknown != total {
+		t.Errorf("标定后 %d 个覆盖格中只有 %d 个识别为覆盖格", total, unknown)
+	}
+}
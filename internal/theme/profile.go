@@ -0,0 +1,208 @@
+package theme
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"sort"
+	"strings"
+
+	"minego/pkg/colorutil"
+	"minego/pkg/imageproc"
+	"minego/pkg/kit"
+)
+
+// BorderMode 在窗口截图中定位雷区的方式
+type BorderMode string
+
+const (
+	BorderByColor BorderMode = "color" // 取主题边框颜色的外接矩形（Win7 的深色网格线）
+	BorderByGrid  BorderMode = "grid"  // 取检测到的网格线的外接矩形（经典版灰色网格线在窗口中并不唯一）
+)
+
+// scoreTolerance 判断单元格颜色与主题背景色吻合的 L1 距离（8 位），容忍 Win7 方块的渐变
+const scoreTolerance = 60
+
+// Profile 一种扫雷客户端的完整配置：窗口定位、雷区定位、网格参数与单元格识别
+type Profile struct {
+	Name        string
+	WindowClass string // 窗口类名，为空时不限制
+	WindowTitle string // 窗口标题，为空时不限制
+	TitlePrefix bool   // 为 true 时 WindowTitle 按前缀匹配（标题中带版本号的客户端）
+	Border      BorderMode
+	BorderInset int    // 截图前窗口边界内缩像素，去掉标题栏阴影等干扰
+	GridExpand  int    // 定位到的雷区向外扩展的像素，保证最外侧网格线在截图内
+	Theme       *Theme // 配色、二值化阈值与模板目录
+}
+
+// classicTheme 经典（XP 及其仿制客户端）配色：灰色凸起方块，灰色网格线，16 色调色板数字。
+// 覆盖格与空白格主色相同，颜色识别器无法区分二者，需要先用 cmd/calibrate 生成模板
+func classicTheme(name string) *Theme {
+	return &Theme{
+		Name:          name,
+		BorderColor:   NewColor(color.RGBA{128, 128, 128, 255}),
+		CoveredColor:  NewColor(color.RGBA{192, 192, 192, 255}),
+		RevealedColor: NewColor(color.RGBA{192, 192, 192, 255}),
+		NumberColors: map[int]Color{
+			1: NewColor(color.RGBA{0, 0, 255, 255}),
+			2: NewColor(color.RGBA{0, 128, 0, 255}),
+			3: NewColor(color.RGBA{255, 0, 0, 255}),
+			4: NewColor(color.RGBA{0, 0, 128, 255}),
+			5: NewColor(color.RGBA{128, 0, 0, 255}),
+			6: NewColor(color.RGBA{0, 128, 128, 255}),
+			7: NewColor(color.RGBA{0, 0, 0, 255}),
+			8: NewColor(color.RGBA{128, 128, 128, 255}),
+		},
+		FlaggedColor:      NewColor(color.RGBA{255, 0, 0, 255}),
+		MineColor:         NewColor(color.RGBA{0, 0, 0, 255}),
+		BinarizeThreshold: 160,
+		Templates:         "templates/" + name,
+	}
+}
+
+var profiles = map[string]*Profile{
+	"win7": {
+		Name:        "win7",
+		WindowClass: "Minesweeper",
+		WindowTitle: "扫雷",
+		Border:      BorderByColor,
+		BorderInset: 10,
+		GridExpand:  3,
+		Theme:       Default(),
+	},
+	"xp": {
+		Name:        "xp",
+		WindowClass: "Minesweeper",
+		WindowTitle: "Minesweeper",
+		Border:      BorderByGrid,
+		BorderInset: 4,
+		GridExpand:  1,
+		Theme:       classicTheme("xp"),
+	},
+	"arbiter": {
+		Name:        "arbiter",
+		WindowTitle: "Minesweeper Arbiter",
+		TitlePrefix: true,
+		Border:      BorderByGrid,
+		BorderInset: 4,
+		GridExpand:  1,
+		Theme:       classicTheme("arbiter"),
+	},
+	"msx": {
+		Name:        "msx",
+		WindowTitle: "Minesweeper X",
+		TitlePrefix: true,
+		Border:      BorderByGrid,
+		BorderInset: 4,
+		GridExpand:  1,
+		Theme:       classicTheme("msx"),
+	},
+}
+
+// ProfileByName 返回内置客户端配置
+func ProfileByName(name string) (*Profile, error) {
+	if p, ok := profiles[name]; ok {
+		return p, nil
+	}
+	return nil, fmt.Errorf("未知客户端 %q，可选: %s", name, strings.Join(ProfileNames(), ", "))
+}
+
+// ProfileNames 返回所有内置客户端名称
+func ProfileNames() []string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Profiles 返回所有内置客户端配置，按名称排序
+func Profiles() []*Profile {
+	list := make([]*Profile, 0, len(profiles))
+	for _, name := range ProfileNames() {
+		list = append(list, profiles[name])
+	}
+	return list
+}
+
+// FindBoard 在窗口截图中定位雷区，返回的矩形已按 GridExpand 扩展并限制在截图范围内
+func (p *Profile) FindBoard(windowImg image.Image) (image.Rectangle, error) {
+	var board image.Rectangle
+	switch p.Border {
+	case BorderByGrid:
+		h, v := imageproc.DetectMineSweeperGridWithThreshold(windowImg, p.threshold())
+		if len(h) < 2 || len(v) < 2 {
+			return image.Rectangle{}, fmt.Errorf("未检测到 %s 的雷区网格", p.Name)
+		}
+		origin := windowImg.Bounds().Min
+		board = image.Rect(v[0], h[0], v[len(v)-1], h[len(h)-1]).Add(origin)
+	default:
+		border := p.Theme.BorderColor
+		if !border.IsSet() || kit.FindLeftmostColor(windowImg, border) == nil {
+			return image.Rectangle{}, fmt.Errorf("未找到 %s 的雷区边框颜色", p.Name)
+		}
+		board = kit.FindSurroundingRect(windowImg, border)
+	}
+	return board.Inset(-p.GridExpand).Intersect(windowImg.Bounds()), nil
+}
+
+// Score 返回雷区截图与该客户端配色的吻合程度（0 到 1）：
+// 按该客户端的阈值检测网格后，统计单元格中心颜色接近覆盖格或空白格主色的比例
+func (p *Profile) Score(boardImg image.Image) float64 {
+	h, v := imageproc.DetectMineSweeperGridWithThreshold(boardImg, p.threshold())
+	if len(h) < 3 || len(v) < 3 {
+		return 0
+	}
+	backgrounds := make([]Color, 0, 2)
+	for _, c := range []Color{p.Theme.CoveredColor, p.Theme.RevealedColor} {
+		if c.IsSet() {
+			backgrounds = append(backgrounds, c)
+		}
+	}
+	if len(backgrounds) == 0 {
+		return 0
+	}
+
+	b := boardImg.Bounds()
+	matched, total := 0, 0
+	for i := range len(h) - 1 {
+		for j := range len(v) - 1 {
+			// 取中心偏左上的位置，避开数字与旗帜
+			x := v[j] + (v[j+1]-v[j])/4
+			y := h[i] + (h[i+1]-h[i])/4
+			c := boardImg.At(b.Min.X+x, b.Min.Y+y)
+			total++
+			for _, bg := range backgrounds {
+				if colorutil.ColorsCloseN(c, bg, scoreTolerance) {
+					matched++
+					break
+				}
+			}
+		}
+	}
+	return float64(matched) / float64(total)
+}
+
+// Detect 从雷区截图中识别客户端，返回得分最高且超过一半格子吻合的配置。
+// 配色相同的客户端（例如各经典版）无法仅凭截图区分，候选应限定为已找到窗口的客户端
+func Detect(boardImg image.Image, candidates []*Profile) (*Profile, error) {
+	var best *Profile
+	bestScore := 0.5
+	for _, p := range candidates {
+		if score := p.Score(boardImg); score > bestScore {
+			best, bestScore = p, score
+		}
+	}
+	if best == nil {
+		return nil, fmt.Errorf("无法从截图识别扫雷客户端")
+	}
+	return best, nil
+}
+
+func (p *Profile) threshold() uint8 {
+	if p.Theme.BinarizeThreshold != 0 {
+		return p.Theme.BinarizeThreshold
+	}
+	return imageproc.BinarizeThreshold
+}
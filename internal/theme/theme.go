@@ -0,0 +1,203 @@
+// Package theme 描述扫雷客户端的配色与识别参数，支持从文件加载并应用到识别流程
+package theme
+
+import (
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"os"
+	"path/filepath"
+
+	"minego/internal/identify"
+	"minego/pkg/imageproc"
+)
+
+// Color 以 "#rrggbb" 形式序列化的颜色，零值表示未设置
+type Color color.RGBA
+
+// NewColor 由 RGBA 颜色创建，透明度固定为不透明
+func NewColor(c color.RGBA) Color {
+	c.A = 255
+	return Color(c)
+}
+
+// RGBA 实现 color.Color
+func (c Color) RGBA() (r, g, b, a uint32) {
+	return color.RGBA(c).RGBA()
+}
+
+// IsSet 颜色是否已设置
+func (c Color) IsSet() bool {
+	return c.A != 0
+}
+
+// MarshalText 实现 encoding.TextMarshaler
+func (c Color) MarshalText() ([]byte, error) {
+	return fmt.Appendf(nil, "#%02x%02x%02x", c.R, c.G, c.B), nil
+}
+
+// UnmarshalText 实现 encoding.TextUnmarshaler
+func (c *Color) UnmarshalText(text []byte) error {
+	var r, g, b uint8
+	if _, err := fmt.Sscanf(string(text), "#%02x%02x%02x", &r, &g, &b); err != nil {
+		return fmt.Errorf("无法解析颜色 %q: %v", text, err)
+	}
+	*c = Color{r, g, b, 255}
+	return nil
+}
+
+// Theme 一套客户端配色与识别参数，未设置的字段在应用时保持当前值
+type Theme struct {
+	Name              string        `json:"name"`
+	BorderColor       Color         `json:"border,omitzero"`            // 雷区网格线/边框颜色
+	CoveredColor      Color         `json:"covered,omitzero"`           // 未翻开格子的主色
+	CoveredRange      int           `json:"coveredRange,omitzero"`      // 覆盖格像素与主色的最大距离（8 位 L1），超出时判为无法观测
+	RevealedColor     Color         `json:"revealed,omitzero"`          // 已翻开空白格的主色
+	NumberColors      map[int]Color `json:"numbers,omitempty"`          // 数字 1-8 的特征色，颜色识别器使用 1-6
+	FlaggedColor      Color         `json:"flagged,omitzero"`           // 旗帜格的特征色
+	MineColor         Color         `json:"mine,omitzero"`              // 翻开地雷的特征色
+	ExplodedColor     Color         `json:"exploded,omitzero"`          // 踩中地雷的背景色
+	MisflagColor      Color         `json:"misflag,omitzero"`           // 插错旗帜上叉的颜色
+	QuestionColor     Color         `json:"question,omitzero"`          // "?" 标记的特征色
+	EmptyMinRed       uint8         `json:"emptyMinRed,omitzero"`       // 颜色识别器判定空白格的中心像素红色分量下限
+	BinarizeThreshold uint8         `json:"binarizeThreshold,omitzero"` // 网格检测的二值化阈值
+	Binarize          string        `json:"binarize,omitempty"`         // 网格线检测的二值化方式：fixed、otsu 或 adaptive
+	CellSize          float64       `json:"cellSize,omitzero"`          // 期望的单元格边长（像素），按周期检测网格时用于校验
+	Templates         string        `json:"templates,omitempty"`        // 模板目录，相对路径以主题文件所在目录为基准
+
+	dir string
+}
+
+// Default 返回当前内置的 Win7 配色
+func Default() *Theme {
+	return &Theme{
+		Name:          "win7",
+		BorderColor:   NewColor(color.RGBA{7, 8, 9, 255}),
+		CoveredColor:  NewColor(identify.CoveredColor), // 覆盖格蓝色渐变的近似主色
+		CoveredRange:  identify.CoveredColorRange,
+		RevealedColor: NewColor(color.RGBA{200, 210, 225, 255}), // 翻开格的近似主色
+		FlaggedColor:  NewColor(identify.FlaggedColor),
+		MineColor:     NewColor(identify.MineColor),
+		ExplodedColor: NewColor(identify.ExplodedColor),
+		MisflagColor:  NewColor(identify.MisflagColor),
+		QuestionColor: NewColor(identify.QuestionMarkColor),
+		NumberColors: map[int]Color{
+			1: NewColor(identify.Number1FeatureColor),
+			2: NewColor(identify.Number2FeatureColor),
+			3: NewColor(identify.Number3Color),
+			4: NewColor(identify.Number4Color),
+			5: NewColor(identify.Number5Color),
+			6: NewColor(identify.Number6Color),
+		},
+		EmptyMinRed:       identify.EmptyMinRed,
+		BinarizeThreshold: imageproc.BinarizeThreshold,
+	}
+}
+
+// Load 读取主题文件
+func Load(path string) (*Theme, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取主题文件失败: %v", err)
+	}
+	t := &Theme{}
+	if err := json.Unmarshal(data, t); err != nil {
+		return nil, fmt.Errorf("解析主题文件失败: %v", err)
+	}
+	t.dir = filepath.Dir(path)
+	return t, nil
+}
+
+// Save 写入主题文件
+func (t *Theme) Save(path string) error {
+	data, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化主题失败: %v", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("写入主题文件失败: %v", err)
+	}
+	return nil
+}
+
+// TemplateDir 返回模板目录的实际路径，未设置时返回空字符串
+func (t *Theme) TemplateDir() string {
+	if t.Templates == "" || filepath.IsAbs(t.Templates) {
+		return t.Templates
+	}
+	return filepath.Join(t.dir, t.Templates)
+}
+
+// HasTemplates 模板目录中是否有模板图像
+func (t *Theme) HasTemplates() bool {
+	dir := t.TemplateDir()
+	if dir == "" {
+		return false
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.png"))
+	return err == nil && len(files) > 0
+}
+
+// Apply 将主题应用到 identify 与 imageproc 的全局参数；模板目录中有模板时改用模板识别器，
+// 没有时（例如尚未标定的经典客户端）保留当前识别器，调用方可用 HasTemplates 检查并提示。
+// 边框颜色由调用方自行使用
+func (t *Theme) Apply() error {
+	numberVars := map[int]*color.RGBA{
+		1: &identify.Number1FeatureColor,
+		2: &identify.Number2FeatureColor,
+		3: &identify.Number3Color,
+		4: &identify.Number4Color,
+		5: &identify.Number5Color,
+		6: &identify.Number6Color,
+	}
+	for n, c := range t.NumberColors {
+		if v, ok := numberVars[n]; ok && c.IsSet() {
+			*v = color.RGBA(c)
+		}
+	}
+	if t.CoveredColor.IsSet() {
+		identify.CoveredColor = color.RGBA(t.CoveredColor)
+	}
+	if t.CoveredRange != 0 {
+		identify.CoveredColorRange = t.CoveredRange
+	}
+	if t.FlaggedColor.IsSet() {
+		identify.FlaggedColor = color.RGBA(t.FlaggedColor)
+	}
+	if t.MineColor.IsSet() {
+		identify.MineColor = color.RGBA(t.MineColor)
+	}
+	if t.ExplodedColor.IsSet() {
+		identify.ExplodedColor = color.RGBA(t.ExplodedColor)
+	}
+	if t.MisflagColor.IsSet() {
+		identify.MisflagColor = color.RGBA(t.MisflagColor)
+	}
+	if t.QuestionColor.IsSet() {
+		identify.QuestionMarkColor = color.RGBA(t.QuestionColor)
+	}
+	if t.EmptyMinRed != 0 {
+		identify.EmptyMinRed = t.EmptyMinRed
+	}
+	if t.BinarizeThreshold != 0 {
+		imageproc.BinarizeThreshold = t.BinarizeThreshold
+	}
+	if t.Binarize != "" {
+		mode, err := imageproc.ParseThresholdMode(t.Binarize)
+		if err != nil {
+			return err
+		}
+		imageproc.BinarizeMode = mode
+	}
+	if t.CellSize != 0 {
+		imageproc.CellSize = t.CellSize
+	}
+	if t.HasTemplates() {
+		templates, err := identify.LoadTemplates(t.TemplateDir())
+		if err != nil {
+			return err
+		}
+		identify.DefaultRecognizer = templates
+	}
+	return nil
+}
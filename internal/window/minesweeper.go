@@ -12,3 +12,18 @@ func GetMineSweeperWindow() winapi.Window {
 	hwnd, _ := findMineWindow()
 	return winapi.NewWindow(hwnd)
 }
+
+// Find 按类名与标题查找窗口，类名或标题为空时不限制该项，prefix 为 true 时标题按前缀匹配
+func Find(className, title string, prefix bool) (winapi.Window, error) {
+	var hwnd winapi.HWND
+	var err error
+	if prefix {
+		hwnd, err = winapi.FindWindowByTitlePrefix(className, title)
+	} else {
+		hwnd, err = winapi.FindWindow(className, title)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return winapi.NewWindow(hwnd), nil
+}
@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"encoding/binary"
 	"image"
+	"image/color"
 	"log"
 
 	_ "image/jpeg"
@@ -15,143 +16,161 @@ import (
 
 func DetectMineSweeperGridNum(img image.Image) (gridRows int, gridCols int) {
 	// 计算格子数（行数和列数）
-	horizontalLines, verticalLines := DetectMineSweeperGrid(img)
-	gridRows = len(horizontalLines) - 1
-	gridCols = len(verticalLines) - 1
+	grid := DetectMineSweeperGrid(img)
+	gridRows = len(grid.HorizontalLines) - 1
+	gridCols = len(grid.VerticalLines) - 1
 	return gridRows, gridCols
 }
 
-// 检测扫雷网格,由始图像中识别出扫雷格子数
-func DetectMineSweeperGrid(img image.Image) ([]int, []int) {
+// BinarizeThreshold 固定阈值（ThresholdFixed）时网格检测的二值化阈值，灰度不高于该值的像素视为网格线，可由主题覆盖
+var BinarizeThreshold uint8 = 60
+
+// 检测扫雷网格,由始图像中识别出扫雷格子数。
+// 优先按周期检测（DetectGridByPeriod），失败时按 BinarizeMode 二值化后检测贯穿的深色网格线，
+// 结果中给出实际使用的二值化方式与阈值
+func DetectMineSweeperGrid(img image.Image) Grid {
 	bounds := img.Bounds()
 	imgWidth, imgHeight := bounds.Dx(), bounds.Dy()
 	log.Println("图像尺寸:", imgWidth, "x", imgHeight)
+	horizontalLines, verticalLines, err := DetectGridByPeriod(img)
+	if err == nil {
+		log.Printf("按周期检测到水平线: %d 列线: %d, 单元格约 %.1f 像素", len(horizontalLines), len(verticalLines),
+			float64(verticalLines[len(verticalLines)-1]-verticalLines[0])/float64(len(verticalLines)-1))
+		return Grid{HorizontalLines: horizontalLines, VerticalLines: verticalLines, Periodic: true}
+	}
+	log.Printf("按周期检测网格失败: %v，改用网格线检测", err)
 	// 转换为灰度图
 	grayImg := toGrayScale(img)
 	log.Println("图像灰度处理完成")
 
 	// 二值化处理
-	binaryImg := binarize(grayImg, 60)
+	grid := Grid{Mode: BinarizeMode}
+	var binaryImg *image.Gray
+	binaryImg, grid.Threshold = binarizeWith(grayImg, BinarizeMode)
+	log.Printf("二值化方式: %s, 阈值: %d", grid.Mode, grid.Threshold)
 	if err := saveDebugImage(binaryImg, "debug_output.bmp"); err != nil {
 		log.Printf("保存调试图像失败: %v\n", err)
 	}
 
-	// 检测水平和垂直线
-	horizontalLines := detectHorizontalLines(binaryImg, imgWidth, imgHeight)
-	verticalLines := detectVerticalLines(binaryImg, imgWidth, imgHeight)
-	// fmt.Println("检测到水平线:", horizontal, "列线:", vertical)
-	if len(horizontalLines) == 0 || len(verticalLines) == 0 {
-		return []int{}, []int{}
-	}
+	grid.HorizontalLines, grid.VerticalLines = gridLines(darkRuns(binaryImg, 0))
+	log.Println("检测到水平线:", len(grid.HorizontalLines), "列线:", len(grid.VerticalLines))
+	return grid
+}
 
-	// 对坐标排序
-	sort.Ints(horizontalLines)
-	sort.Ints(verticalLines)
+// DetectMineSweeperGridWithThreshold 使用指定二值化阈值检测扫雷网格，不输出日志与调试图像
+func DetectMineSweeperGridWithThreshold(img image.Image, threshold uint8) ([]int, []int) {
+	return gridLines(darkRuns(img, threshold))
+}
 
-	// 聚类和去重（合并相近的线）
-	horizontalLines = clusterPoints(horizontalLines, 10)
-	verticalLines = clusterPoints(verticalLines, 10)
-	log.Println("检测到水平线:", len(horizontalLines), "列线:", len(verticalLines))
-	return horizontalLines, verticalLines
+// runStats 每行、每列最长的连续深色像素数
+type runStats struct {
+	rows []int
+	cols []int
 }
 
-// 转换为灰度图
-func toGrayScale(img image.Image) [][]uint8 {
+// darkRuns 一次遍历完成灰度转换、阈值比较与行列连续深色像素统计，灰度不高于 threshold 的像素为深色。
+// *image.RGBA 与 *image.Gray 直接读取 Pix，不分配中间图像
+func darkRuns(img image.Image, threshold uint8) runStats {
 	bounds := img.Bounds()
 	width, height := bounds.Dx(), bounds.Dy()
-	gray := make([][]uint8, height)
-
+	stats := runStats{rows: make([]int, height), cols: make([]int, width)}
+	colRun := make([]int, width)
+	row := make([]uint8, width)
 	for y := range height {
-		gray[y] = make([]uint8, width)
-		for x := range width {
-			r, g, b, _ := img.At(img.Bounds().Min.X+x, img.Bounds().Min.Y+y).RGBA()
-			// 转换为灰度值 (0.299*R + 0.587*G + 0.114*B)
-			grayValue := uint8((0.299*float64(r>>8) + 0.587*float64(g>>8) + 0.114*float64(b>>8)))
-			gray[y][x] = grayValue
+		grayRow(img, bounds.Min.Y+y, row)
+		run, longest := 0, 0
+		for x, v := range row {
+			if v <= threshold {
+				run++
+				colRun[x]++
+				continue
+			}
+			longest = max(longest, run)
+			run = 0
+			stats.cols[x] = max(stats.cols[x], colRun[x])
+			colRun[x] = 0
 		}
+		stats.rows[y] = max(longest, run)
 	}
-
-	return gray
+	for x, run := range colRun {
+		stats.cols[x] = max(stats.cols[x], run)
+	}
+	return stats
 }
 
-// 二值化处理
-func binarize(gray [][]uint8, threshold uint8) [][]uint8 {
-	height := len(gray)
-	width := len(gray[0])
-	binary := make([][]uint8, height)
-
-	for y := range height {
-		binary[y] = make([]uint8, width)
-		for x := range width {
-			if gray[y][x] > threshold {
-				binary[y][x] = 255 // 白色
-			} else {
-				binary[y][x] = 0 // 黑色
-			}
+// grayRow 把图像第 y 行（绝对坐标）的灰度写入 row，len(row) 为图像宽度
+func grayRow(img image.Image, y int, row []uint8) {
+	bounds := img.Bounds()
+	switch src := img.(type) {
+	case *image.RGBA:
+		pix := src.Pix[src.PixOffset(bounds.Min.X, y):]
+		for x := range row {
+			row[x] = gray8(pix[4*x], pix[4*x+1], pix[4*x+2])
+		}
+	case *image.Gray:
+		copy(row, src.Pix[src.PixOffset(bounds.Min.X, y):])
+	default:
+		for x := range row {
+			row[x] = Gray(img.At(bounds.Min.X+x, y))
 		}
 	}
-
-	return binary
 }
 
-// 检测水平线
-func detectHorizontalLines(binary [][]uint8, width, height int) []int {
-	lines := make([]int, 0, height) // 预分配容量
-	if width <= 0 || height <= 0 {
-		return lines // 处理无效输入
-	}
-	requiredLength := width / 2
-
-	for y := range height {
-		lineLength := 0
-		maxLineLength := 0
-
-		for x := range width {
-			if binary[y][x] == 0 {
-				lineLength++
-			} else {
-				maxLineLength = max(maxLineLength, lineLength)
-				lineLength = 0
-			}
+// gridLines 最长连续深色像素不短于图像宽度（高度）一半的行（列）即为网格线，合并相近的线
+func gridLines(stats runStats) ([]int, []int) {
+	width, height := len(stats.cols), len(stats.rows)
+	horizontalLines := make([]int, 0, height)
+	for y, run := range stats.rows {
+		if run >= width/2 {
+			horizontalLines = append(horizontalLines, y)
 		}
-		maxLineLength = max(maxLineLength, lineLength) // 合并末尾处理
-
-		if maxLineLength >= requiredLength {
-			lines = append(lines, y)
+	}
+	verticalLines := make([]int, 0, width)
+	for x, run := range stats.cols {
+		if run >= height/2 {
+			verticalLines = append(verticalLines, x)
 		}
 	}
+	if len(horizontalLines) == 0 || len(verticalLines) == 0 {
+		return []int{}, []int{}
+	}
 
-	return lines
+	// 聚类和去重（合并相近的线）
+	horizontalLines = clusterPoints(horizontalLines, 10)
+	verticalLines = clusterPoints(verticalLines, 10)
+	return horizontalLines, verticalLines
 }
 
-// 检测垂直线
-func detectVerticalLines(binary [][]uint8, width, height int) []int {
-	lines := make([]int, 0, width) // 预分配容量
-	if width <= 0 || height <= 0 {
-		return lines // 处理无效输入
+// 转换为连续存储的灰度图，原点与原图相同
+func toGrayScale(img image.Image) *image.Gray {
+	bounds := img.Bounds()
+	gray := image.NewGray(bounds)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		grayRow(img, y, gray.Pix[gray.PixOffset(bounds.Min.X, y):][:bounds.Dx()])
 	}
-	requiredLength := height / 2
+	return gray
+}
 
-	for x := 0; x < width; x++ {
-		lineLength := 0
-		maxLineLength := 0
+// Gray 返回颜色的灰度值 (0.299*R + 0.587*G + 0.114*B)
+func Gray(c color.Color) uint8 {
+	r, g, b, _ := c.RGBA()
+	return gray8(uint8(r>>8), uint8(g>>8), uint8(b>>8))
+}
 
-		for y := range height {
-			if binary[y][x] == 0 { // 黑色像素表示线
-				lineLength++
-			} else {
-				maxLineLength = max(maxLineLength, lineLength)
-				lineLength = 0
-			}
-		}
-		maxLineLength = max(maxLineLength, lineLength) // 合并末尾处理
+// gray8 用整数运算计算灰度并向下取整，比浮点运算快，且不会因舍入误差少算 1
+func gray8(r, g, b uint8) uint8 {
+	return uint8((299*uint32(r) + 587*uint32(g) + 114*uint32(b)) / 1000)
+}
 
-		if maxLineLength >= requiredLength {
-			lines = append(lines, x)
+// 二值化处理：灰度不高于阈值的像素为黑色（0），其余为白色（255）
+func binarize(gray *image.Gray, threshold uint8) *image.Gray {
+	binary := image.NewGray(gray.Bounds())
+	for i, v := range gray.Pix {
+		if v > threshold {
+			binary.Pix[i] = 255
 		}
 	}
-
-	return lines
+	return binary
 }
 
 // 聚类点 - 合并接近的点
@@ -177,12 +196,12 @@ func clusterPoints(points []int, tolerance int) []int {
 }
 
 // 保存调试图像（可选）
-func saveDebugImage(bin [][]uint8, path string) error {
-	height := len(bin)
+func saveDebugImage(bin *image.Gray, path string) error {
+	bounds := bin.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
 	if height == 0 {
 		return nil
 	}
-	width := len(bin[0])
 
 	file, err := os.Create(path)
 	if err != nil {
@@ -224,9 +243,7 @@ func saveDebugImage(bin [][]uint8, path string) error {
 
 	// 像素数据（从底部向上）
 	for y := height - 1; y >= 0; y-- {
-		for x := range width {
-			writer.WriteByte(bin[y][x])
-		}
+		writer.Write(bin.Pix[bin.PixOffset(bounds.Min.X, bounds.Min.Y+y):][:width])
 		// 行填充
 		for range padding {
 			writer.WriteByte(0)
@@ -0,0 +1,136 @@
+package imageproc_test
+
+import (
+	"image"
+	"io"
+	"log"
+	"slices"
+	"sort"
+	"testing"
+
+	"minego/internal/degrade"
+	"minego/internal/render"
+	"minego/pkg/imageproc"
+)
+
+// referenceGridLines 单次遍历（darkRuns）之前的实现：先生成逐行分配的灰度图（浮点运算）与二值图，
+// 再分别按行、按列统计最长的连续深色像素，用作对照。只支持 *image.RGBA
+func referenceGridLines(img image.Image, threshold uint8) ([]int, []int) {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	rgba := img.(*image.RGBA)
+	gray := make([][]uint8, height)
+	for y := range height {
+		gray[y] = make([]uint8, width)
+		pix := rgba.Pix[rgba.PixOffset(bounds.Min.X, bounds.Min.Y+y):]
+		for x := range width {
+			gray[y][x] = uint8(0.299*float64(pix[4*x]) + 0.587*float64(pix[4*x+1]) + 0.114*float64(pix[4*x+2]))
+		}
+	}
+	binary := make([][]uint8, height)
+	for y := range height {
+		binary[y] = make([]uint8, width)
+		for x := range width {
+			if gray[y][x] > threshold {
+				binary[y][x] = 255
+			}
+		}
+	}
+	longest := func(n int, dark func(k int) bool) int {
+		run, best := 0, 0
+		for k := range n {
+			if dark(k) {
+				run++
+				best = max(best, run)
+			} else {
+				run = 0
+			}
+		}
+		return best
+	}
+	horizontalLines := make([]int, 0)
+	for y := range height {
+		if longest(width, func(x int) bool { return binary[y][x] == 0 }) >= width/2 {
+			horizontalLines = append(horizontalLines, y)
+		}
+	}
+	verticalLines := make([]int, 0)
+	for x := range width {
+		if longest(height, func(y int) bool { return binary[y][x] == 0 }) >= height/2 {
+			verticalLines = append(verticalLines, x)
+		}
+	}
+	if len(horizontalLines) == 0 || len(verticalLines) == 0 {
+		return []int{}, []int{}
+	}
+	return cluster(horizontalLines), cluster(verticalLines)
+}
+
+// cluster 与 clusterPoints 相同：合并 10 像素以内的线，保留每组第一条
+func cluster(points []int) []int {
+	sort.Ints(points)
+	clustered := []int{points[0]}
+	for _, p := range points[1:] {
+		if p-clustered[len(clustered)-1] > 10 {
+			clustered = append(clustered, p)
+		}
+	}
+	return clustered
+}
+
+func TestDetectMineSweeperGridWithThresholdMatchesReference(t *testing.T) {
+	for seed := uint64(1); seed <= 3; seed++ {
+		board := render.Board(degrade.SampleBoard(testRows, testCols, 99, seed), testCellSize)
+		for _, d := range degrade.Standard() {
+			img := d.Apply(board)
+			for _, threshold := range []uint8{imageproc.BinarizeThreshold, imageproc.OtsuThreshold(img)} {
+				gotH, gotV := imageproc.DetectMineSweeperGridWithThreshold(img, threshold)
+				wantH, wantV := referenceGridLines(img, threshold)
+				if !slices.Equal(gotH, wantH) || !slices.Equal(gotV, wantV) {
+					t.Errorf("种子 %d %s 阈值 %d: 网格线 %v %v, 原实现 %v %v", seed, d.Name, threshold, gotH, gotV, wantH, wantV)
+				}
+			}
+		}
+	}
+}
+
+// benchCapture 渲染 16x30（高级）盘面，单元格为默认大小
+func benchCapture() *image.RGBA {
+	return render.Board(degrade.SampleBoard(16, 30, 99, 1), render.DefaultCellSize)
+}
+
+func BenchmarkDetectGrid(b *testing.B) {
+	img := benchCapture()
+	b.Run("reference", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			referenceGridLines(img, imageproc.BinarizeThreshold)
+		}
+	})
+	b.Run("period", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			imageproc.DetectGridByPeriod(img)
+		}
+	})
+	for _, mode := range []imageproc.ThresholdMode{imageproc.ThresholdFixed, imageproc.ThresholdOtsu, imageproc.ThresholdAdaptive} {
+		b.Run(mode.String(), func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				imageproc.DetectGrid(img, mode)
+			}
+		})
+	}
+}
+
+func BenchmarkDetectMineSweeperGrid(b *testing.B) {
+	img := benchCapture()
+	// 检测过程的日志会淹没基准结果
+	w := log.Writer()
+	log.SetOutput(io.Discard)
+	b.Cleanup(func() { log.SetOutput(w) })
+	b.ReportAllocs()
+	for b.Loop() {
+		imageproc.DetectMineSweeperGrid(img)
+	}
+}
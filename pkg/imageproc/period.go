@@ -0,0 +1,259 @@
+package imageproc
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"sort"
+)
+
+var (
+	// CellSize 期望的单元格边长（像素），为 0 时只要求在 MinCellSize 到 MaxCellSize 之间，可由主题覆盖
+	CellSize float64
+	// CellSizeTolerance 检测到的单元格边长与 CellSize 的最大相对偏差
+	CellSizeTolerance = 0.25
+	MinCellSize       = 8
+	MaxCellSize       = 96
+)
+
+const (
+	minPeriodContrast = 1.5  // 网格线处的边缘强度至少是平均值的这么多倍，否则视为没有周期结构
+	minLinePresence   = 0.5  // 首尾网格线之间至少这么多比例的位置能看到边缘
+	maxPitchMismatch  = 0.1  // 单元格是正方形，水平与垂直周期的最大相对差
+	pitchStep         = 0.02 // 细化周期的步长（像素）
+	phaseStep         = 0.1  // 细化相位的步长（像素）
+)
+
+// DetectGridByPeriod 按周期检测扫雷网格：由图像的边缘投影估计单元格边长（周期）与网格线位置（相位），
+// 返回首尾网格线之间等间距的完整网格线。只依赖格子之间的明暗变化，不要求有贯穿的深色网格线，
+// 数字与旗帜不会产生多余的线，高 DPI 下的细线也不会漏检
+func DetectGridByPeriod(img image.Image) ([]int, []int, error) {
+	if img.Bounds().Empty() {
+		return nil, nil, fmt.Errorf("图像为空")
+	}
+	rowProfile, colProfile := edgeProfiles(img)
+
+	lo, hi := MinCellSize, MaxCellSize
+	if CellSize > 0 {
+		lo = max(lo, int(math.Floor(CellSize*(1-CellSizeTolerance))))
+		hi = min(hi, int(math.Ceil(CellSize*(1+CellSizeTolerance))))
+	}
+	hi = min(hi, len(rowProfile)/2, len(colProfile)/2)
+	if lo > hi {
+		return nil, nil, fmt.Errorf("图像 %dx%d 太小，容纳不下两个 %d 像素以上的单元格", len(colProfile), len(rowProfile), lo)
+	}
+	lag := commonPeriod(rowProfile, colProfile, lo, hi)
+
+	rowPitch, rowPhase, err := refinePeriod(rowProfile, lag)
+	if err != nil {
+		return nil, nil, fmt.Errorf("水平网格线%v", err)
+	}
+	colPitch, colPhase, err := refinePeriod(colProfile, lag)
+	if err != nil {
+		return nil, nil, fmt.Errorf("垂直网格线%v", err)
+	}
+	if math.Abs(rowPitch-colPitch) > maxPitchMismatch*max(rowPitch, colPitch) {
+		return nil, nil, fmt.Errorf("单元格高 %.1f 像素、宽 %.1f 像素，不是正方形", rowPitch, colPitch)
+	}
+	if CellSize > 0 {
+		for _, pitch := range []float64{rowPitch, colPitch} {
+			if math.Abs(pitch-CellSize) > CellSizeTolerance*CellSize {
+				return nil, nil, fmt.Errorf("单元格边长 %.1f 像素与期望的 %.1f 像素相差过大", pitch, CellSize)
+			}
+		}
+	}
+
+	horizontalLines, err := evenLines(rowProfile, rowPitch, rowPhase)
+	if err != nil {
+		return nil, nil, fmt.Errorf("水平网格线%v", err)
+	}
+	verticalLines, err := evenLines(colProfile, colPitch, colPhase)
+	if err != nil {
+		return nil, nil, fmt.Errorf("垂直网格线%v", err)
+	}
+	return horizontalLines, verticalLines, nil
+}
+
+// edgeProfiles 返回每行、每列的边缘强度：像素与上下（左右）相邻像素的灰度差之和。
+// 一像素宽的网格线在线上取得最大值，没有网格线的两格交界处在交界两侧取得相同的值。
+// 逐行转换灰度并累加差值，只保留相邻两行
+func edgeProfiles(img image.Image) (rows, cols []float64) {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	// dy[y] 为第 y-1 行与第 y 行的差，dx[x] 为第 x-1 列与第 x 列的差
+	dy := make([]int, height+1)
+	dx := make([]int, width+1)
+	previous, current := make([]uint8, width), make([]uint8, width)
+	for y := range height {
+		grayRow(img, bounds.Min.Y+y, current)
+		sum := 0
+		for x, v := range current {
+			if y > 0 {
+				sum += absInt(int(v) - int(previous[x]))
+			}
+			if x > 0 {
+				dx[x] += absInt(int(v) - int(current[x-1]))
+			}
+		}
+		dy[y] = sum
+		previous, current = current, previous
+	}
+	rows = make([]float64, height)
+	for y := range rows {
+		rows[y] = float64(dy[y] + dy[y+1])
+	}
+	cols = make([]float64, width)
+	for x := range cols {
+		cols[x] = float64(dx[x] + dx[x+1])
+	}
+	return rows, cols
+}
+
+// commonPeriod 在 [lo, hi] 中选取两个方向自相关之和最大的整数周期。
+// 周期的整数倍同样相关，取相关性与最大值相近的最短周期（局部极大值）
+func commonPeriod(a, b []float64, lo, hi int) int {
+	score := make([]float64, hi+2)
+	for _, profile := range [][]float64{a, b} {
+		ac := autocorrelation(profile, hi+1)
+		for lag := lo; lag <= hi+1; lag++ {
+			score[lag] += ac[lag]
+		}
+	}
+	best := lo
+	for lag := lo; lag <= hi; lag++ {
+		if score[lag] > score[best] {
+			best = lag
+		}
+	}
+	for lag := lo; lag < best; lag++ {
+		if score[lag] >= 0.7*score[best] && score[lag] >= score[lag-1] && score[lag] >= score[lag+1] {
+			return lag
+		}
+	}
+	return best
+}
+
+// autocorrelation 返回去均值后的归一化自相关，下标为位移量，最大到 maxLag
+func autocorrelation(profile []float64, maxLag int) []float64 {
+	n := len(profile)
+	mean := mean(profile)
+	variance := 0.0
+	for _, v := range profile {
+		variance += (v - mean) * (v - mean)
+	}
+	ac := make([]float64, maxLag+1)
+	if variance == 0 {
+		return ac
+	}
+	variance /= float64(n)
+	for lag := range min(maxLag+1, n) {
+		sum := 0.0
+		for x := 0; x+lag < n; x++ {
+			sum += (profile[x] - mean) * (profile[x+lag] - mean)
+		}
+		ac[lag] = sum / float64(n-lag) / variance
+	}
+	return ac
+}
+
+// refinePeriod 在整数周期 lag 附近搜索亚像素精度的周期与相位，使等间距位置上的平均边缘强度最大
+func refinePeriod(profile []float64, lag int) (pitch, phase float64, err error) {
+	best := -1.0
+	for p := float64(lag) - 1.5; p <= float64(lag)+1.5; p += pitchStep {
+		for ph := 0.0; ph < p; ph += phaseStep {
+			if s := combScore(profile, p, ph); s > best {
+				best, pitch, phase = s, p, ph
+			}
+		}
+	}
+	average := mean(profile)
+	if average == 0 || best < minPeriodContrast*average {
+		return 0, 0, fmt.Errorf("没有明显的周期结构")
+	}
+	// 每个周期内等分点处的边缘同样强时，真实的单元格更小，说明期望的边长范围不对
+	for k := 2; k <= 3; k++ {
+		weakest := best
+		for i := 1; i < k; i++ {
+			weakest = min(weakest, combScore(profile, pitch, phase+pitch*float64(i)/float64(k)))
+		}
+		if weakest >= 0.7*best {
+			return 0, 0, fmt.Errorf("周期约为 %.1f 像素，小于允许的单元格边长", pitch/float64(k))
+		}
+	}
+	return pitch, phase, nil
+}
+
+// combScore 返回 phase + k*pitch 处边缘强度（线性插值）的平均值
+func combScore(profile []float64, pitch, phase float64) float64 {
+	sum, count := 0.0, 0
+	for t := phase; t <= float64(len(profile)-1); t += pitch {
+		i := int(t)
+		f := t - float64(i)
+		v := profile[i]
+		if f > 0 {
+			v += f * (profile[i+1] - v)
+		}
+		sum += v
+		count++
+	}
+	if count == 0 {
+		return 0
+	}
+	return sum / float64(count)
+}
+
+// evenLines 取边缘明显的首尾两条网格线，返回二者之间按周期等间距排列的全部网格线
+func evenLines(profile []float64, pitch, phase float64) ([]int, error) {
+	positions := make([]int, 0)
+	strength := make([]float64, 0)
+	for t := phase; t <= float64(len(profile)-1); t += pitch {
+		x := int(math.Round(t))
+		positions = append(positions, x)
+		s := profile[x]
+		if x > 0 {
+			s = max(s, profile[x-1])
+		}
+		if x+1 < len(profile) {
+			s = max(s, profile[x+1])
+		}
+		strength = append(strength, s)
+	}
+
+	// 以较强的四分之一位置为参照，边框外的位置几乎没有边缘；同时要求明显强于整体平均值
+	sorted := append([]float64(nil), strength...)
+	sort.Sort(sort.Reverse(sort.Float64Slice(sorted)))
+	threshold := max(sorted[len(sorted)/4]/3, minPeriodContrast*mean(profile))
+	first, last, present := -1, -1, 0
+	for k, s := range strength {
+		if s < threshold {
+			continue
+		}
+		if first < 0 {
+			first = k
+		}
+		last = k
+		present++
+	}
+	if first < 0 || last-first < 1 {
+		return nil, fmt.Errorf("不足两条")
+	}
+	if float64(present) < minLinePresence*float64(last-first+1) {
+		return nil, fmt.Errorf("在 %d 个位置中只看到 %d 条，不像等间距网格", last-first+1, present)
+	}
+	return positions[first : last+1], nil
+}
+
+func mean(values []float64) float64 {
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+func absInt(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,194 @@
+package imageproc
+
+import (
+	"fmt"
+	"image"
+)
+
+// ThresholdMode 网格检测的二值化方式
+type ThresholdMode int
+
+const (
+	ThresholdFixed    ThresholdMode = iota // 使用 BinarizeThreshold
+	ThresholdOtsu                          // 按整幅图像的灰度直方图用 Otsu 法计算阈值
+	ThresholdAdaptive                      // 与邻域平均灰度比较，适应不均匀的亮度
+)
+
+var (
+	// BinarizeMode DetectMineSweeperGrid 退回网格线检测时的二值化方式，可由主题覆盖
+	BinarizeMode = ThresholdOtsu
+	// AdaptiveRadius 自适应二值化的邻域半径（像素），应小于单元格边长
+	AdaptiveRadius = 7
+	// AdaptiveOffset 自适应二值化时比邻域平均灰度暗这么多才视为网格线
+	AdaptiveOffset = 20
+)
+
+var thresholdModeNames = map[ThresholdMode]string{
+	ThresholdFixed:    "fixed",
+	ThresholdOtsu:     "otsu",
+	ThresholdAdaptive: "adaptive",
+}
+
+func (m ThresholdMode) String() string {
+	if name, ok := thresholdModeNames[m]; ok {
+		return name
+	}
+	return fmt.Sprintf("ThresholdMode(%d)", int(m))
+}
+
+// ParseThresholdMode 由名称（fixed、otsu 或 adaptive）解析二值化方式
+func ParseThresholdMode(name string) (ThresholdMode, error) {
+	for m, n := range thresholdModeNames {
+		if n == name {
+			return m, nil
+		}
+	}
+	return 0, fmt.Errorf("未知二值化方式 %q，可选: fixed, otsu, adaptive", name)
+}
+
+// Grid 网格检测的结果
+type Grid struct {
+	HorizontalLines []int
+	VerticalLines   []int
+	Periodic        bool // 按周期检测（DetectGridByPeriod），没有二值化，Mode 与 Threshold 无意义
+	Mode            ThresholdMode
+	Threshold       uint8 // 实际使用的阈值；自适应模式下为各像素局部阈值的平均值
+}
+
+// DetectGrid 按 mode 二值化后检测贯穿的深色网格线，结果中给出实际使用的阈值。
+// 全局阈值（固定与 Otsu）直接在原图上统计连续深色像素，不生成灰度图与二值图
+func DetectGrid(img image.Image, mode ThresholdMode) Grid {
+	grid := Grid{Mode: mode}
+	if img.Bounds().Empty() {
+		return grid
+	}
+	var stats runStats
+	switch mode {
+	case ThresholdOtsu:
+		grid.Threshold = OtsuThreshold(img)
+		stats = darkRuns(img, grid.Threshold)
+	case ThresholdAdaptive:
+		var binaryImg *image.Gray
+		binaryImg, grid.Threshold = binarizeAdaptive(toGrayScale(img), AdaptiveRadius, AdaptiveOffset)
+		stats = darkRuns(binaryImg, 0)
+	default:
+		grid.Threshold = BinarizeThreshold
+		stats = darkRuns(img, grid.Threshold)
+	}
+	grid.HorizontalLines, grid.VerticalLines = gridLines(stats)
+	return grid
+}
+
+// binarizeWith 按 mode 二值化，返回二值图与实际使用的阈值
+func binarizeWith(gray *image.Gray, mode ThresholdMode) (*image.Gray, uint8) {
+	switch mode {
+	case ThresholdOtsu:
+		threshold := OtsuThreshold(gray)
+		return binarize(gray, threshold), threshold
+	case ThresholdAdaptive:
+		return binarizeAdaptive(gray, AdaptiveRadius, AdaptiveOffset)
+	default:
+		return binarize(gray, BinarizeThreshold), BinarizeThreshold
+	}
+}
+
+// OtsuThreshold 用三类 Otsu 法把灰度分为网格线、较暗的格子与较亮的格子，返回前两类之间的阈值。
+// 网格线只占少量像素，两类 Otsu 往往把覆盖格与翻开格分开，而不是把网格线与格子分开
+func OtsuThreshold(img image.Image) uint8 {
+	var hist [256]float64
+	bounds := img.Bounds()
+	row := make([]uint8, bounds.Dx())
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		grayRow(img, y, row)
+		for _, v := range row {
+			hist[v]++
+		}
+	}
+	// 前缀和：像素数与灰度总和，类间方差只需区间的像素数与均值
+	var count, sum [257]float64
+	for i := range 256 {
+		count[i+1] = count[i] + hist[i]
+		sum[i+1] = sum[i] + float64(i)*hist[i]
+	}
+	// term 返回灰度区间 [lo, hi) 对类间方差的贡献 n*mean^2（总均值为常数，可略去）
+	term := func(lo, hi int) float64 {
+		n := count[hi] - count[lo]
+		if n == 0 {
+			return 0
+		}
+		s := sum[hi] - sum[lo]
+		return s * s / n
+	}
+	best, threshold := -1.0, 0
+	for t1 := 1; t1 < 255; t1++ {
+		if count[t1] == 0 {
+			continue
+		}
+		low := term(0, t1)
+		for t2 := t1 + 1; t2 < 256; t2++ {
+			if v := low + term(t1, t2) + term(t2, 256); v > best {
+				best, threshold = v, t1
+			}
+		}
+	}
+	// binarize 把不高于阈值的像素视为网格线，最暗一类为 [0, threshold)
+	return uint8(max(threshold-1, 0))
+}
+
+// binarizeAdaptive 比上下两侧 radius 宽的半邻域、同时比左右两侧的半邻域都暗 offset 以上的像素视为网格线，
+// 每对半邻域取较暗的一侧。返回二值图与局部阈值的平均值。只与整个邻域的平均值比较，
+// 或只满足其中一对时，紧挨明亮边框的格子内部也会显得偏暗
+func binarizeAdaptive(gray *image.Gray, radius, offset int) (*image.Gray, uint8) {
+	bounds := gray.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	// 积分图，integral[y*(width+1)+x] 为 [0,y)x[0,x) 的灰度和
+	stride := width + 1
+	integral := make([]int, (height+1)*stride)
+	for y := range height {
+		pix := gray.Pix[y*gray.Stride:]
+		rowSum := 0
+		for x := range width {
+			rowSum += int(pix[x])
+			integral[(y+1)*stride+x+1] = integral[y*stride+x+1] + rowSum
+		}
+	}
+	// average 返回 [y0,y1)x[x0,x1) 的平均灰度，区域为空时返回 -1
+	average := func(y0, y1, x0, x1 int) int {
+		area := (y1 - y0) * (x1 - x0)
+		if area <= 0 {
+			return -1
+		}
+		return (integral[y1*stride+x1] - integral[y0*stride+x1] - integral[y1*stride+x0] + integral[y0*stride+x0]) / area
+	}
+
+	binary := image.NewGray(bounds)
+	thresholdSum := 0
+	for y := range height {
+		pix := gray.Pix[y*gray.Stride:]
+		out := binary.Pix[y*binary.Stride:]
+		y0, y1 := max(y-radius, 0), min(y+radius+1, height)
+		for x := range width {
+			x0, x1 := max(x-radius, 0), min(x+radius+1, width)
+			vertical := darker(average(y0, y, x0, x1), average(y+1, y1, x0, x1))
+			horizontal := darker(average(y0, y1, x0, x), average(y0, y1, x+1, x1))
+			threshold := max(min(vertical, horizontal)-offset, 0)
+			thresholdSum += threshold
+			if int(pix[x]) > threshold {
+				out[x] = 255
+			}
+		}
+	}
+	return binary, uint8(thresholdSum / (width * height))
+}
+
+// darker 返回两侧平均灰度中较暗的一个，只有一侧在图像内时取该侧
+func darker(a, b int) int {
+	switch {
+	case a < 0:
+		return b
+	case b < 0:
+		return a
+	default:
+		return min(a, b)
+	}
+}
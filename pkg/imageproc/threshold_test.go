@@ -0,0 +1,158 @@
+package imageproc_test
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"slices"
+	"testing"
+
+	"minego/internal/cell"
+	"minego/internal/degrade"
+	"minego/internal/render"
+	"minego/pkg/imageproc"
+)
+
+const (
+	testRows     = 16
+	testCols     = 30
+	testCellSize = 24
+)
+
+// lighting 亮度与对比度调整：v' = (v-128)*contrast + 128 + brightness
+type lighting struct {
+	brightness int
+	contrast   float64
+}
+
+func (l lighting) String() string {
+	return fmt.Sprintf("亮度%+d对比度%.2f", l.brightness, l.contrast)
+}
+
+func (l lighting) color(c color.RGBA) color.RGBA {
+	channel := func(v uint8) uint8 {
+		return uint8(min(max((float64(v)-128)*l.contrast+128+float64(l.brightness), 0), 255))
+	}
+	return color.RGBA{channel(c.R), channel(c.G), channel(c.B), c.A}
+}
+
+func (l lighting) apply(img *image.RGBA) *image.RGBA {
+	out := image.NewRGBA(img.Bounds())
+	for i := 0; i < len(img.Pix); i += 4 {
+		c := l.color(color.RGBA{img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3]})
+		out.Pix[i], out.Pix[i+1], out.Pix[i+2], out.Pix[i+3] = c.R, c.G, c.B, c.A
+	}
+	return out
+}
+
+var lightings = []lighting{
+	{0, 1},
+	{60, 1},
+	{-40, 1},
+	{0, 0.5},
+	{40, 0.6},
+	{-20, 1.4},
+}
+
+// testBoards 新开局（全部覆盖）与对局中途的盘面
+func testBoards() map[string][][]cell.GridCell {
+	fresh := degrade.SampleBoard(testRows, testCols, 0, 1)
+	for _, row := range fresh {
+		for j := range row {
+			row[j].State = cell.Unknown
+		}
+	}
+	return map[string][][]cell.GridCell{
+		"新开局": fresh,
+		"中途":  degrade.SampleBoard(testRows, testCols, 99, 1),
+	}
+}
+
+func TestDetectGridThresholdModes(t *testing.T) {
+	wantH, wantV := render.Lines(testRows, testCols, testCellSize)
+	for name, truth := range testBoards() {
+		board := render.Board(truth, testCellSize)
+		for _, l := range lightings {
+			img := l.apply(board)
+			lineGray := imageproc.Gray(l.color(render.LineColor))
+			coveredGray := imageproc.Gray(l.color(render.CoveredColor))
+			revealedGray := imageproc.Gray(l.color(render.RevealedColor))
+			for _, mode := range []imageproc.ThresholdMode{imageproc.ThresholdOtsu, imageproc.ThresholdAdaptive} {
+				t.Run(fmt.Sprintf("%s/%s/%s", name, l, mode), func(t *testing.T) {
+					grid := imageproc.DetectGrid(img, mode)
+					if grid.Mode != mode {
+						t.Errorf("Mode = %s, want %s", grid.Mode, mode)
+					}
+					if len(grid.HorizontalLines) != testRows+1 || len(grid.VerticalLines) != testCols+1 {
+						t.Fatalf("检测到 %d 条水平线、%d 条垂直线，应为 %d、%d",
+							len(grid.HorizontalLines), len(grid.VerticalLines), testRows+1, testCols+1)
+					}
+					if !slices.Equal(grid.HorizontalLines, wantH) || !slices.Equal(grid.VerticalLines, wantV) {
+						t.Errorf("网格线 %v %v, want %v %v", grid.HorizontalLines, grid.VerticalLines, wantH, wantV)
+					}
+					// 阈值必须把网格线与最暗的格子分开
+					upper := min(coveredGray, revealedGray)
+					if mode == imageproc.ThresholdAdaptive {
+						upper = max(coveredGray, revealedGray)
+					}
+					if grid.Threshold < lineGray || grid.Threshold >= upper {
+						t.Errorf("阈值 %d 不在网格线灰度 %d 与格子灰度 %d 之间", grid.Threshold, lineGray, upper)
+					}
+				})
+			}
+		}
+	}
+}
+
+func TestOtsuThresholdFollowsBrightness(t *testing.T) {
+	board := render.Board(degrade.SampleBoard(testRows, testCols, 99, 2), testCellSize)
+	dark := imageproc.OtsuThreshold(lighting{-40, 1}.apply(board))
+	bright := imageproc.OtsuThreshold(lighting{60, 1}.apply(board))
+	if bright <= dark {
+		t.Errorf("变亮后阈值 %d 不高于变暗时的 %d", bright, dark)
+	}
+}
+
+func TestDetectGridWindow(t *testing.T) {
+	// 带信息栏的整个窗口：数码管面板同样是深色，只有贯穿雷区的线才是网格线
+	truth := degrade.SampleBoard(testRows, testCols, 99, 3)
+	img, boardRect := render.Window(truth, testCellSize, 99, 0, 0)
+	wantH, wantV := render.Lines(testRows, testCols, testCellSize)
+	for i := range wantH {
+		wantH[i] += boardRect.Min.Y
+	}
+	for _, mode := range []imageproc.ThresholdMode{imageproc.ThresholdOtsu, imageproc.ThresholdAdaptive} {
+		grid := imageproc.DetectGrid(img, mode)
+		if !slices.Equal(grid.HorizontalLines, wantH) || !slices.Equal(grid.VerticalLines, wantV) {
+			t.Errorf("%s: 网格线 %v %v, want %v %v", mode, grid.HorizontalLines, grid.VerticalLines, wantH, wantV)
+		}
+	}
+}
+
+func TestDetectMineSweeperGridReportsThreshold(t *testing.T) {
+	// 回退到网格线检测时会在当前目录写调试图像
+	t.Chdir(t.TempDir())
+	board := render.Board(degrade.SampleBoard(testRows, testCols, 99, 4), testCellSize)
+
+	grid := imageproc.DetectMineSweeperGrid(board)
+	if !grid.Periodic || len(grid.VerticalLines) != testCols+1 {
+		t.Fatalf("按周期检测失败: Periodic=%v, %d 条垂直线", grid.Periodic, len(grid.VerticalLines))
+	}
+
+	// 期望的边长与实际不符时周期检测失败，退回按 BinarizeMode 的网格线检测
+	defer func(size float64, mode imageproc.ThresholdMode) {
+		imageproc.CellSize, imageproc.BinarizeMode = size, mode
+	}(imageproc.CellSize, imageproc.BinarizeMode)
+	imageproc.CellSize = 60
+	imageproc.BinarizeMode = imageproc.ThresholdOtsu
+	grid = imageproc.DetectMineSweeperGrid(board)
+	if grid.Periodic || grid.Mode != imageproc.ThresholdOtsu {
+		t.Fatalf("Periodic=%v Mode=%s，应退回 Otsu 网格线检测", grid.Periodic, grid.Mode)
+	}
+	if want := imageproc.OtsuThreshold(board); grid.Threshold != want {
+		t.Errorf("Threshold = %d, want %d", grid.Threshold, want)
+	}
+	if len(grid.HorizontalLines) != testRows+1 || len(grid.VerticalLines) != testCols+1 {
+		t.Errorf("检测到 %d 条水平线、%d 条垂直线", len(grid.HorizontalLines), len(grid.VerticalLines))
+	}
+}
@@ -0,0 +1,55 @@
+package click
+
+import (
+	"fmt"
+	"image"
+	"unsafe"
+)
+
+const (
+	SM_CXCURSOR = 13 // 鼠标指针宽度
+	SM_CYCURSOR = 14 // 鼠标指针高度
+)
+
+var procGetCursorPos = moduser32.NewProc("GetCursorPos")
+
+type point struct {
+	X, Y int32
+}
+
+// CursorPos 返回鼠标指针的物理坐标
+func CursorPos() (image.Point, error) {
+	var p point
+	r, _, err := procGetCursorPos.Call(uintptr(unsafe.Pointer(&p)))
+	if r == 0 {
+		return image.Point{}, fmt.Errorf("获取鼠标位置失败: %v", err)
+	}
+	return image.Point{X: int(p.X), Y: int(p.Y)}, nil
+}
+
+// CursorRect 返回鼠标指针可能遮挡的屏幕区域（以热点为左上角的指针图像大小）
+func CursorRect() (image.Rectangle, error) {
+	p, err := CursorPos()
+	if err != nil {
+		return image.Rectangle{}, err
+	}
+	size := image.Point{X: int(GetSystemMetrics(SM_CXCURSOR)), Y: int(GetSystemMetrics(SM_CYCURSOR))}
+	return image.Rectangle{Min: p, Max: p.Add(size)}, nil
+}
+
+// Move 把鼠标指针移动到指定物理坐标，不点击
+func Move(p image.Point) {
+	primaryWidth, primaryHeight := GetPrimaryMonitorResolution()
+	input := INPUT{
+		Type: INPUT_MOUSE,
+		Mi: MOUSEINPUT{
+			Dx:      int32(float64(p.X) / float64(primaryWidth-1) * 65535),
+			Dy:      int32(float64(p.Y) / float64(primaryHeight-1) * 65535),
+			DwFlags: MOUSEEVENTF_ABSOLUTE | MOUSEEVENTF_MOVE,
+		},
+	}
+	r, _, err := procSendInput.Call(1, uintptr(unsafe.Pointer(&input)), unsafe.Sizeof(input))
+	if r == 0 {
+		fmt.Printf("SendInput失败: %v\n", err)
+	}
+}
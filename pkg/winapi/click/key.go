@@ -0,0 +1,47 @@
+package click
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+const (
+	INPUT_KEYBOARD  = 1
+	KEYEVENTF_KEYUP = 0x0002
+
+	VK_F2 = 0x71 // 扫雷的"新游戏"快捷键
+)
+
+type KEYBDINPUT struct {
+	WVk         uint16
+	WScan       uint16
+	DwFlags     uint32
+	Time        uint32
+	DwExtraInfo uintptr
+}
+
+// keyboardInput 与 INPUT 布局一致，联合体按最大成员 MOUSEINPUT 补齐
+type keyboardInput struct {
+	Type    uint32
+	Ki      KEYBDINPUT
+	padding [unsafe.Sizeof(MOUSEINPUT{}) - unsafe.Sizeof(KEYBDINPUT{})]byte
+}
+
+// PressKey 向前台窗口发送一次按键（按下并抬起）
+func PressKey(vk uint16) {
+	inputs := []keyboardInput{
+		{Type: INPUT_KEYBOARD, Ki: KEYBDINPUT{WVk: vk}},
+		{Type: INPUT_KEYBOARD, Ki: KEYBDINPUT{WVk: vk, DwFlags: KEYEVENTF_KEYUP}},
+	}
+	size := unsafe.Sizeof(keyboardInput{})
+	for _, input := range inputs {
+		r, _, err := procSendInput.Call(
+			1,
+			uintptr(unsafe.Pointer(&input)),
+			uintptr(size),
+		)
+		if r == 0 {
+			fmt.Printf("SendInput失败: %v\n", err)
+		}
+	}
+}
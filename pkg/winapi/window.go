@@ -3,6 +3,7 @@ package winapi
 import (
 	"fmt"
 	"image"
+	"strings"
 	"syscall"
 	"unsafe"
 )
@@ -19,11 +20,15 @@ var (
 	setForegroundWindow = user32.NewProc("SetForegroundWindow")
 	showWindow          = user32.NewProc("ShowWindow")
 	getWindowRect       = user32.NewProc("GetWindowRect")
+	enumWindows         = user32.NewProc("EnumWindows")
+	getWindowText       = user32.NewProc("GetWindowTextW")
+	getClassName        = user32.NewProc("GetClassNameW")
+	isWindowVisible     = user32.NewProc("IsWindowVisible")
 )
 
+// FindWindow 按类名和标题查找窗口，空字符串表示不限制该项
 func FindWindow(className, windowName string) (HWND, error) {
-	classPtr, _ := syscall.UTF16PtrFromString(className)
-	windowPtr, _ := syscall.UTF16PtrFromString(windowName)
+	classPtr, windowPtr := utf16PtrOrNil(className), utf16PtrOrNil(windowName)
 
 	hwnd, _, _ := findWindow.Call(
 		uintptr(unsafe.Pointer(classPtr)),
@@ -36,6 +41,45 @@ func FindWindow(className, windowName string) (HWND, error) {
 	return hwnd, nil
 }
 
+// FindWindowByTitlePrefix 查找标题以 prefix 开头的可见窗口，className 为空时不限制类名
+func FindWindowByTitlePrefix(className, prefix string) (HWND, error) {
+	var found HWND
+	callback := syscall.NewCallback(func(hwnd HWND, _ uintptr) uintptr {
+		if visible, _, _ := isWindowVisible.Call(hwnd); visible == 0 {
+			return 1
+		}
+		if className != "" && windowString(getClassName, hwnd) != className {
+			return 1
+		}
+		if strings.HasPrefix(windowString(getWindowText, hwnd), prefix) {
+			found = hwnd
+			return 0 // 停止枚举
+		}
+		return 1
+	})
+	enumWindows.Call(callback, 0)
+
+	if found == 0 {
+		return 0, fmt.Errorf("window with title prefix %q not found", prefix)
+	}
+	return found, nil
+}
+
+func windowString(proc *syscall.LazyProc, hwnd HWND) string {
+	buf := make([]uint16, 256)
+	n, _, _ := proc.Call(hwnd, uintptr(unsafe.Pointer(&buf[0])), uintptr(len(buf)))
+	return syscall.UTF16ToString(buf[:n])
+}
+
+// utf16PtrOrNil 空字符串返回 nil，对应 Win32 API 中的 NULL
+func utf16PtrOrNil(s string) *uint16 {
+	if s == "" {
+		return nil
+	}
+	ptr, _ := syscall.UTF16PtrFromString(s)
+	return ptr
+}
+
 func FindMineWindow() (HWND, error) {
 	className := "Minesweeper"
 	windowName := "扫雷"